@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use: "cache",
+	Short: "Manage rph's cache",
+	Long: `Manage rph's cache. Responses from the artifactory are cached so
+completions are instant and rph keeps working without internet.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// This is a noop to stop the root command from requiring a robot project
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"log/slog"
+	"rph/cmd/vendordep"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheclearCmd represents the cache clear command
+var cacheclearCmd = &cobra.Command{
+	Use: "clear",
+	Short: "Forget every cached artifactory response",
+	Long: `Forget every cached artifactory response, the next request for each of
+them will go to the artifactory. Cached vendordeps and maven artifacts are kept.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := responseCache().Clear()
+		if err != nil {
+			slog.Error("Failed to clear the cache", "path", vendordep.HttpCachePath(), "error", err)
+			return err
+		}
+
+		slog.Info("Cleared the cache", "path", vendordep.HttpCachePath())
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheclearCmd)
+}
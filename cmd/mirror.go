@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// mirrorCmd represents the mirror command
+var mirrorCmd = &cobra.Command{
+	Use: "mirror",
+	Short: "Share your rph cache with other machines",
+	Long: `Share your rph cache with other machines, this is intended for events
+where there's no internet but one laptop in the pits has everything cached.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// This is a noop to stop the root command from requiring a robot project
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+}
@@ -0,0 +1,241 @@
+package mirror
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the same format artifactory uses for timestamps
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+const (
+	marketplacePath = "vendordeps/vendordep-marketplace"
+	mavenPath = "maven"
+)
+
+// Server serves the rph cache in the same shape as the artifactory storage api
+// so that other rph instances can use it in place of frcmaven.
+type Server struct {
+	// VendordepDir the rph vendordep cache, sorted into a directory per year
+	VendordepDir string
+	// MavenDir a maven repository, as made by rph vendordep fetch-artifacts
+	MavenDir string
+}
+
+type child struct {
+	URI string `json:"uri"`
+	Folder bool `json:"folder"`
+}
+
+type checksums struct {
+	Sha1 string `json:"sha1"`
+	Md5 string `json:"md5"`
+	Sha256 string `json:"sha256"`
+}
+
+type storageInfo struct {
+	Repo string `json:"repo"`
+	Path string `json:"path"`
+	LastModified string `json:"lastModified,omitempty"`
+	DownloadUri string `json:"downloadUri,omitempty"`
+	Size string `json:"size,omitempty"`
+	Checksums *checksums `json:"checksums,omitempty"`
+	Children *[]child `json:"children,omitempty"`
+}
+
+// virtualDirs directories which only exist to hold the real ones
+var virtualDirs = map[string][]child{
+	".": { { URI: "/vendordeps", Folder: true }, { URI: "/" + mavenPath, Folder: true } },
+	"vendordeps": { { URI: "/vendordep-marketplace", Folder: true } },
+}
+
+// private directories in the vendordep cache which aren't served
+var private = map[string]bool{ "trash": true, "edited": true }
+
+// cutDir cut dir off the front of name, returning "." when name is dir
+func cutDir(name string, dir string) (string, bool) {
+	if name == dir {
+		return ".", true
+	}
+	return strings.CutPrefix(name, dir + "/")
+}
+
+// resolve find where a path in the mirror lives on disk
+func (s Server) resolve(name string) (fs.FS, string, error) {
+	if rest, ok := cutDir(name, marketplacePath); ok {
+		// the trash and edited vendordeps are in the vendordep cache but they're
+		// not for sharing
+		if private[strings.SplitN(rest, "/", 2)[0]] {
+			return nil, "", fs.ErrNotExist
+		}
+		return os.DirFS(s.VendordepDir), rest, nil
+	}
+
+	if rest, ok := cutDir(name, mavenPath); ok {
+		return os.DirFS(s.MavenDir), rest, nil
+	}
+
+	return nil, "", fs.ErrNotExist
+}
+
+func (s Server) storage(w http.ResponseWriter, r *http.Request, name string) {
+	info := storageInfo{ Path: "/" }
+	if name != "." {
+		info.Repo = strings.SplitN(name, "/", 2)[0]
+		info.Path = "/" + name
+	}
+
+	if children, ok := virtualDirs[name]; ok {
+		info.Children = &children
+		writeJson(w, info)
+		return
+	}
+
+	fsys, rest, err := s.resolve(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	stat, err := fs.Stat(fsys, rest)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	info.LastModified = stat.ModTime().UTC().Format(timeFormat)
+
+	if stat.IsDir() {
+		entries, err := fs.ReadDir(fsys, rest)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		children := []child{}
+		for _, e := range entries {
+			if name == marketplacePath && private[e.Name()] { continue }
+			children = append(children, child{ URI: "/" + e.Name(), Folder: e.IsDir() })
+		}
+		info.Children = &children
+
+		writeJson(w, info)
+		return
+	}
+
+	sums, err := checksum(fsys, rest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	info.Size = strconv.FormatInt(stat.Size(), 10)
+	info.Checksums = sums
+	info.DownloadUri = "http://" + r.Host + "/" + name
+	writeJson(w, info)
+}
+
+func (s Server) file(w http.ResponseWriter, r *http.Request, name string) {
+	fsys, rest, err := s.resolve(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := fsys.Open(rest)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	rs, ok := file.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "file is not seekable", http.StatusInternalServerError)
+		return
+	}
+
+	http.ServeContent(w, r, stat.Name(), stat.ModTime(), rs)
+}
+
+func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	slog.Info("Request", "remote", r.RemoteAddr, "path", r.URL.Path)
+
+	name := strings.Trim(r.URL.Path, "/")
+	if kind, ok := strings.CutPrefix(name, "api/search/"); ok {
+		s.search(w, r, kind)
+		return
+	}
+	if rest, ok := strings.CutPrefix(name, "api/storage"); ok {
+		rest = strings.Trim(rest, "/")
+		if rest == "" {
+			rest = "."
+		}
+		s.storage(w, r, path.Clean(rest))
+		return
+	}
+
+	s.file(w, r, path.Clean(name))
+}
+
+// Serve listen on addr until the server fails
+func (s Server) Serve(addr string) error {
+	server := &http.Server{
+		Addr: addr,
+		Handler: s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func checksum(fsys fs.FS, name string) (*checksums, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	s1, s5, s256 := sha1.New(), md5.New(), sha256.New()
+	if _, err := io.Copy(io.MultiWriter(s1, s5, s256), file); err != nil {
+		return nil, err
+	}
+
+	sum := func(h hash.Hash) string { return hex.EncodeToString(h.Sum(nil)) }
+	return &checksums{ Sha1: sum(s1), Md5: sum(s5), Sha256: sum(s256) }, nil
+}
+
+func writeJson(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,231 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rph/cmd/vendordep/artifactory"
+	"slices"
+	"testing"
+)
+
+const testJar = "maven/com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar"
+
+// testMirror serve a vendordep cache and maven repository out of temporary
+// directories, the artifactory client is pointed at it
+func testMirror(t *testing.T) (artifactory.ArtifactoryFS, Server) {
+	t.Helper()
+
+	s := Server{ VendordepDir: t.TempDir(), MavenDir: t.TempDir() }
+	files := map[string]string{
+		filepath.Join(s.VendordepDir, "2025", "REVLib-2025.0.3.json"): `{"name":"REVLib"}`,
+		filepath.Join(s.VendordepDir, "2025", "photonlib-v2025.3.1.json"): `{"name":"photonlib"}`,
+		filepath.Join(s.VendordepDir, "trash", "20250101T000000.000000000-Secret.json"): `{"name":"Secret"}`,
+		filepath.Join(s.VendordepDir, "edited", "0123abcd.json"): `{"name":"Edited"}`,
+		filepath.Join(s.MavenDir, "com", "revrobotics", "frc", "REVLib-java", "2025.0.3", "REVLib-java-2025.0.3.jar"): "jar",
+		filepath.Join(s.MavenDir, "com", "revrobotics", "frc", "REVLib-driver", "2025.0.3", "REVLib-driver-2025.0.3-linuxathena.zip"): "zip",
+	}
+	for p, content := range files {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return artifactory.New(srv.URL), s
+}
+
+func names(entries []fs.DirEntry) []string {
+	var n []string
+	for _, e := range entries {
+		n = append(n, e.Name())
+	}
+	return n
+}
+
+func TestMirrorReadDir(t *testing.T) {
+	afs, _ := testMirror(t)
+
+	tests := map[string][]string{
+		".": { "maven", "vendordeps" },
+		"vendordeps": { "vendordep-marketplace" },
+		// the trash and edited vendordeps aren't shared
+		"vendordeps/vendordep-marketplace": { "2025" },
+		"vendordeps/vendordep-marketplace/2025": { "REVLib-2025.0.3.json", "photonlib-v2025.3.1.json" },
+		"maven/com/revrobotics/frc": { "REVLib-driver", "REVLib-java" },
+	}
+
+	for dir, want := range tests {
+		entries, err := afs.ReadDir(dir)
+		if err != nil {
+			t.Errorf("ReadDir(%s) = %v", dir, err)
+			continue
+		}
+		if got := names(entries); !slices.Equal(got, want) {
+			t.Errorf("ReadDir(%s) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestMirrorHidesPrivateDirs(t *testing.T) {
+	afs, _ := testMirror(t)
+
+	for _, name := range []string{
+		"vendordeps/vendordep-marketplace/trash",
+		"vendordeps/vendordep-marketplace/trash/20250101T000000.000000000-Secret.json",
+		"vendordeps/vendordep-marketplace/edited/0123abcd.json",
+	} {
+		if _, err := afs.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%s) = %v, want fs.ErrNotExist", name, err)
+		}
+		if _, err := afs.ReadFile(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ReadFile(%s) = %v, want fs.ErrNotExist", name, err)
+		}
+	}
+
+	results, err := afs.SearchArtifact(context.Background(), "*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Name() == "20250101T000000.000000000-Secret.json" || r.Name() == "0123abcd.json" {
+			t.Errorf("search found the private file %s", r.Path)
+		}
+	}
+}
+
+func TestMirrorOpen(t *testing.T) {
+	afs, _ := testMirror(t)
+
+	file, err := afs.Open("vendordeps/vendordep-marketplace/2025/REVLib-2025.0.3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"name":"REVLib"}` {
+		t.Errorf("read %s", data)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, ok := info.Sys().(*artifactory.FileMeta)
+	if !ok {
+		t.Fatalf("Sys() = %T, want *artifactory.FileMeta", info.Sys())
+	}
+	if meta.Checksums.Sha256 == "" || meta.Checksums.Sha1 == "" || meta.Checksums.Md5 == "" {
+		t.Errorf("mirror didn't send every checksum: %+v", meta.Checksums)
+	}
+	if err := meta.Verify(data); err != nil {
+		t.Error(err)
+	}
+	if err := meta.Verify([]byte("corrupt")); err == nil {
+		t.Error("Verify accepted the wrong data")
+	}
+}
+
+func TestMirrorStat(t *testing.T) {
+	afs, _ := testMirror(t)
+
+	info, err := afs.Stat(testJar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.IsDir() || info.Size() != 3 || info.Name() != "REVLib-java-2025.0.3.jar" || info.ModTime().IsZero() {
+		t.Errorf("Stat = %s dir %v size %d modified %s", info.Name(), info.IsDir(), info.Size(), info.ModTime())
+	}
+
+	info, err = afs.Stat("maven/com/revrobotics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsDir() {
+		t.Error("a maven group isn't a directory")
+	}
+
+	if _, err := afs.Stat("maven/com/nothing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a missing file = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func resultPaths(results []artifactory.SearchResult) []string {
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestMirrorSearchArtifact(t *testing.T) {
+	afs, _ := testMirror(t)
+	ctx := context.Background()
+
+	results, err := afs.SearchArtifact(ctx, "REVLib*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"maven/com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip",
+		testJar,
+		"vendordeps/vendordep-marketplace/2025/REVLib-2025.0.3.json",
+	}
+	if got := resultPaths(results); !slices.Equal(got, want) {
+		t.Errorf("SearchArtifact = %v, want %v", got, want)
+	}
+
+	results, err = afs.SearchArtifact(ctx, "REVLib*", "vendordeps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{ "vendordeps/vendordep-marketplace/2025/REVLib-2025.0.3.json" }
+	if got := resultPaths(results); !slices.Equal(got, want) {
+		t.Errorf("SearchArtifact in vendordeps = %v, want %v", got, want)
+	}
+}
+
+func TestMirrorSearchGAVC(t *testing.T) {
+	afs, _ := testMirror(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		g, a, v, c string
+		want []string
+	}{
+		{ "com.revrobotics.frc", "REVLib-java", "", "", []string{ testJar } },
+		{ "com.revrobotics*", "", "2025.0.3", "", []string{
+			"maven/com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip",
+			testJar,
+		} },
+		{ "", "", "", "linuxathena", []string{
+			"maven/com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip",
+		} },
+		// an exact group doesn't match the groups under it
+		{ "com.revrobotics", "", "", "", nil },
+		{ "", "", "2024.*", "", nil },
+	}
+
+	for _, tt := range tests {
+		results, err := afs.SearchGAVC(ctx, tt.g, tt.a, tt.v, tt.c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resultPaths(results); !slices.Equal(got, tt.want) {
+			t.Errorf("SearchGAVC(%q, %q, %q, %q) = %v, want %v", tt.g, tt.a, tt.v, tt.c, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package mirror
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+type searchResult struct {
+	URI string `json:"uri"`
+}
+
+// walk call fn with the mirror path of every file in the mirror which is in one
+// of repos, or every file when repos is empty
+func (s Server) walk(repos []string, fn func(name string)) error {
+	roots := []struct{ name, dir string }{
+		{ marketplacePath, s.VendordepDir },
+		{ mavenPath, s.MavenDir },
+	}
+
+	for _, root := range roots {
+		repo := strings.SplitN(root.name, "/", 2)[0]
+		if len(repos) > 0 && !slices.Contains(repos, repo) { continue }
+
+		err := fs.WalkDir(os.DirFS(root.dir), ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil { return err }
+			if d.IsDir() {
+				if root.name == marketplacePath && private[p] {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			fn(path.Join(root.name, p))
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// glob match a gavc coordinate which may use * and ? wildcards, an empty
+// pattern matches everything
+func glob(pattern string, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+// search answer the artifact and gavc searches by walking the mirror
+func (s Server) search(w http.ResponseWriter, r *http.Request, kind string) {
+	query := r.URL.Query()
+
+	var repos []string
+	if query.Get("repos") != "" {
+		repos = strings.Split(query.Get("repos"), ",")
+	}
+
+	var match func(name string) bool
+	switch kind {
+	case "artifact":
+		pattern := query.Get("name")
+		if pattern == "" {
+			http.Error(w, "name is required", http.StatusBadRequest)
+			return
+		}
+		match = func(name string) bool {
+			ok, _ := path.Match(pattern, path.Base(name))
+			return ok
+		}
+	case "gavc":
+		g, a, v, c := query.Get("g"), query.Get("a"), query.Get("v"), query.Get("c")
+		if g == "" && a == "" && v == "" && c == "" {
+			http.Error(w, "at least one of g, a, v or c is required", http.StatusBadRequest)
+			return
+		}
+		match = func(name string) bool {
+			// maven/group/as/dirs/artifactId/version/file
+			rest, ok := cutDir(name, mavenPath)
+			dirs := strings.Split(rest, "/")
+			if !ok || len(dirs) < 4 {
+				return false
+			}
+
+			n := len(dirs)
+			file := dirs[n - 1]
+			return glob(g, strings.Join(dirs[:n - 3], ".")) &&
+				glob(a, dirs[n - 3]) &&
+				glob(v, dirs[n - 2]) &&
+				(c == "" || strings.Contains(file, "-" + c + "."))
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	results := []searchResult{}
+	err := s.walk(repos, func(name string) {
+		if match(name) {
+			results = append(results, searchResult{ URI: "http://" + r.Host + "/api/storage/" + name })
+		}
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJson(w, map[string][]searchResult{ "results": results })
+}
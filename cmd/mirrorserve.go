@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"log/slog"
+	"rph/cmd/mirror"
+	"rph/cmd/vendordep"
+
+	"github.com/spf13/cobra"
+)
+
+// mirrorserveCmd represents the mirror serve command
+var mirrorserveCmd = &cobra.Command{
+	Use: "serve",
+	Short: "Serve your cached vendordeps and maven artifacts over http",
+	Long: `Serve your cached vendordeps and maven artifacts over http. The server
+answers the same artifactory storage api rph uses to talk to frcmaven, so
+other machines can install vendordeps from it with --artifactory.
+
+Cached vendordeps are served under vendordeps/vendordep-marketplace/<year> and
+the maven repository from rph vendordep fetch-artifacts is served under maven.
+The artifact and gavc search apis are answered as well, AQL is not.
+
+Examples:
+  rph mirror serve --addr :8080
+  # then on another machine
+  rph vendordep add --artifactory http://laptop:8080 photonlib-v2025.3.1
+  rph vendordep fetch-artifacts --artifactory http://laptop:8080`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, err := cmd.Flags().GetString("addr")
+		if err != nil { return err }
+
+		server := mirror.Server{
+			VendordepDir: vendordep.CachePath(),
+			MavenDir: vendordep.MavenCachePath(),
+		}
+
+		slog.Info("Serving the rph cache", "addr", addr)
+		err = server.Serve(addr)
+		if err != nil {
+			slog.Error("Mirror server failed", "error", err)
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	mirrorCmd.AddCommand(mirrorserveCmd)
+
+	mirrorserveCmd.Flags().StringP("addr", "a", ":8080", "The address to listen on.")
+}
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"errors"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"rph/cmd/vendordep"
+	"rph/cmd/vendordep/artifactory"
 	"rph/state"
 	"rph/utils"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -14,6 +19,8 @@ import (
 var projectFs fs.FS
 var projectDir string
 
+const artifactoryEnv = "RPH_ARTIFACTORY"
+
 var rootCmd = &cobra.Command{
 	Use: state.Name,
 	Short: "Manage your FRC robot code the UNIX way.",
@@ -51,6 +58,7 @@ actually run your robot code you will still need to install wpilib.`,
 
 func Execute() {
 	rootCmd.PersistentFlags().String("project-dir", ".", "Set the project directory.")
+	rootCmd.PersistentFlags().StringSlice("artifactory", nil, "Artifactory urls to try in order (also $" + artifactoryEnv + " or \"artifactory\" in the config file).")
 
 	err := rootCmd.Execute()
 	if err != nil {
@@ -58,6 +66,65 @@ func Execute() {
 	}
 }
 
+// artifactoryUrls get the artifactories to use in the order they should be
+// tried, the --artifactory flag takes priority over the environment which takes
+// priority over the config file.
+func artifactoryUrls(cmd *cobra.Command) []string {
+	if urls, err := cmd.Flags().GetStringSlice("artifactory"); err == nil && len(urls) > 0 {
+		return urls
+	}
+
+	if env := os.Getenv(artifactoryEnv); env != "" {
+		return strings.Split(env, ",")
+	}
+
+	config, err := state.LoadConfig()
+	if err != nil {
+		slog.Warn("Unable to load config file", "path", state.ConfigPath, "error", err)
+	} else if len(config.Artifactory) > 0 {
+		return config.Artifactory
+	}
+
+	return []string{ artifactory.DefaultVendorDepArtifactoryUrl }
+}
+
+// configDuration parse a duration from the config file, falling back on def
+// when it isn't set or can't be parsed
+func configDuration(key string, value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+
+	d, err := utils.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Invalid duration in config file, using the default", key, value, "error", err)
+		return def
+	}
+	return d
+}
+
+// responseCache the cache for artifactory responses, using the ttl from the
+// config file when there is one
+func responseCache() *artifactory.Cache {
+	config, err := state.LoadConfig()
+	if err != nil {
+		slog.Warn("Unable to load config file", "path", state.ConfigPath, "error", err)
+	}
+
+	ttl := configDuration("cacheTtl", config.CacheTTL, artifactory.DefaultCacheTTL)
+	return artifactory.NewCache(vendordep.HttpCachePath(), ttl)
+}
+
+// setupArtifactory configure how the vendordep package talks to the
+// artifactories
+func setupArtifactory(cmd *cobra.Command) {
+	vendordep.ArtifactoryUrls = artifactoryUrls(cmd)
+	vendordep.ResponseCache = responseCache()
+
+	config, _ := state.LoadConfig()
+	vendordep.Timeout = configDuration("timeout", config.Timeout, artifactory.DefaultTimeout)
+}
+
 func superPersistentPreRun(cmd *cobra.Command, args []string) {
 	if parent := cmd.Parent(); parent != nil {
 		if parent.PersistentPreRunE != nil {
@@ -71,6 +138,10 @@ func superPersistentPreRun(cmd *cobra.Command, args []string) {
 	}
 }
 
+// errNotInProject returned by commands which need a project when they aren't
+// run in one, so scripts see them fail
+var errNotInProject = errors.New("not in a project directory")
+
 // inProjectDir handles the log message for you
 func inProjectDir() bool {
 	_, err := os.Stat(filepath.Join(projectDir, ".wpilib", "wpilib_preferences.json"))
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"rph/cmd/template"
 	"rph/cmd/vendordep"
 	"strings"
 
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +31,50 @@ func vendorDepsComp(cmd *cobra.Command, args []string, toComplete string) ([]cob
 	return completions, cobra.ShellCompDirectiveNoFileComp
 }
 
+// projectYear get the year to look for vendordeps in, this is the --year flag
+// if it was set and otherwise the year of the current project.
+func projectYear(cmd *cobra.Command) (string, error) {
+	year, err := cmd.Flags().GetString("year")
+	if err != nil { return "", err }
+	if year != "" { return year, nil }
+
+	return wpilibYear()
+}
+
+// wpilibYear get the year the current project is for
+func wpilibYear() (string, error) {
+	file, err := os.Open(filepath.Join(projectDir, ".wpilib", "wpilib_preferences.json"))
+	if err != nil { return "", err }
+	defer file.Close()
+
+	var wpilibPrefs template.WpilibPreferences
+	if err := json.NewDecoder(file).Decode(&wpilibPrefs); err != nil {
+		return "", err
+	}
+
+	return wpilibPrefs.Year, nil
+}
+
+// confirm ask the user a yes or no question, when there's no terminal to ask in
+// the answer is always no
+func confirm(title string, description string) (bool, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return false, nil
+	}
+
+	answer := true
+	err := huh.NewConfirm().
+		Title(title).
+		Description(description).
+		Value(&answer).
+		Run()
+	if err == huh.ErrUserAborted {
+		os.Exit(130)
+	}
+
+	return answer, err
+}
+
 // vendordepCmd represents the vendordep command
 var vendordepCmd = &cobra.Command{
 	Use: "vendordep",
@@ -34,6 +84,7 @@ var vendordepCmd = &cobra.Command{
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		superPersistentPreRun(cmd, args)
 		vendordep.MkCacheDir()
+		setupArtifactory(cmd)
 	},
 }
 
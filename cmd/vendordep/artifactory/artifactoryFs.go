@@ -1,140 +1,448 @@
 package artifactory
 
 import (
-	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"hash"
 	"io"
 	"io/fs"
+	"log/slog"
 	"net/http"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type ArtifactoryFS struct {
-	BaseURL string
+	// BaseURLs the artifactory instances to use, they're tried in order until
+	// one of them can be reached
+	BaseURLs []string
 	Client *http.Client
+	// Timeout how long to wait for an artifactory to start responding. Reading
+	// the body isn't limited so large files can be streamed, use a context
+	// for that. Zero waits forever.
+	Timeout time.Duration
+	// Cache where responses are kept, nil means nothing is cached
+	Cache *Cache
+	// only use what's in Cache, set once none of the artifactories could be
+	// reached
+	offline bool
 }
 
-func New(baseURL string) ArtifactoryFS {
+var (
+	_ fs.ReadDirFS = ArtifactoryFS{}
+	_ fs.ReadFileFS = ArtifactoryFS{}
+	_ fs.StatFS = ArtifactoryFS{}
+	_ fs.GlobFS = ArtifactoryFS{}
+)
+
+// Checksums the hashes artifactory keeps for a file, hex encoded. Any of them
+// may be empty if the artifactory didn't give it to us.
+type Checksums struct {
+	Sha1 string `json:"sha1"`
+	Sha256 string `json:"sha256"`
+	Md5 string `json:"md5"`
+}
+
+// FileMeta extra information about a file, returned by Sys
+type FileMeta struct {
+	// URL where the file was actually downloaded from
+	URL string
+	Checksums Checksums
+}
+
+// Verify check data against the strongest checksum the artifactory gave us,
+// when it didn't give us any there's nothing to check and data is trusted
+func (m *FileMeta) Verify(data []byte) error {
+	var h hash.Hash
+	var want string
+	switch {
+	case m.Checksums.Sha256 != "":
+		h, want = sha256.New(), m.Checksums.Sha256
+	case m.Checksums.Sha1 != "":
+		h, want = sha1.New(), m.Checksums.Sha1
+	case m.Checksums.Md5 != "":
+		h, want = md5.New(), m.Checksums.Md5
+	default:
+		return nil
+	}
+
+	h.Write(data)
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s got %s", m.URL, want, got)
+	}
+	return nil
+}
+
+// DefaultTimeout how long to wait for an artifactory to respond
+const DefaultTimeout = 10 * time.Second
+
+// files up to this size are downloaded in one go and cached, anything bigger is
+// streamed
+const maxCachedSize = 1 << 20
+
+func New(baseURLs ...string) ArtifactoryFS {
+	urls := make([]string, len(baseURLs))
+	for i, u := range baseURLs {
+		urls[i] = strings.TrimRight(u, "/") + "/"
+	}
+
 	return ArtifactoryFS{
-		BaseURL: strings.TrimRight(baseURL, "/") + "/",
-		Client: &http.Client{Timeout: 10 * time.Second},
+		BaseURLs: urls,
+		Client: &http.Client{},
+		Timeout: DefaultTimeout,
 	}
 }
 
+// GetUrl get the url of a file on the first artifactory
 func (afs ArtifactoryFS) GetUrl(name string) string {
 	cleanName := path.Clean(name)
-	return afs.BaseURL + cleanName
+	if len(afs.BaseURLs) == 0 {
+		return cleanName
+	}
+	return afs.BaseURLs[0] + cleanName
 }
 
-func (afs ArtifactoryFS) Open(name string) (fs.File, error) {
-	cleanName := path.Clean(name)
-	url := afs.BaseURL + cleanName
+// errUnavailable the artifactory couldn't be reached or is broken, the next
+// one should be tried
+type errUnavailable struct {
+	err error
+}
 
-	// Fetch metadata via storage API
-	metaURL := afs.BaseURL + "api/storage/" + cleanName
-	if cleanName == "." {
-		metaURL = afs.BaseURL + "api/storage/"
+func (e errUnavailable) Error() string { return e.err.Error() }
+func (e errUnavailable) Unwrap() error { return e.err }
+
+// IsUnavailable check if an error was caused by none of the artifactories being
+// reachable
+func IsUnavailable(err error) bool {
+	var unavailable errUnavailable
+	return errors.As(err, &unavailable)
+}
+
+// cancelBody a response body which cancels its request's context once closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// do send a request, treating connection errors, server errors and taking
+// longer than Timeout to respond as a sign to try the next artifactory
+func (afs ArtifactoryFS) do(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+
+	var timer *time.Timer
+	if afs.Timeout > 0 {
+		timer = time.AfterFunc(afs.Timeout, cancel)
+	}
+
+	resp, err := afs.Client.Do(req.WithContext(ctx))
+	// the timer may have gone off after we got a response but before we could
+	// stop it, in which case the body has already been cancelled
+	if err == nil && timer != nil && !timer.Stop() {
+		resp.Body.Close()
+		err = context.DeadlineExceeded
+	}
+	if err != nil {
+		cancel()
+		// the caller gave up, there's no point trying anything else
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+		return nil, errUnavailable{err}
+	}
+	resp.Body = cancelBody{ resp.Body, cancel }
+
+	if resp.StatusCode >= 500 {
+		resp.Body.Close()
+		return nil, errUnavailable{errors.New("unexpected status: " + resp.Status)}
 	}
 
-	resp, err := afs.Client.Get(metaURL)
+	return resp, nil
+}
+
+// get fetch a url through the cache, when there is one
+func (afs ArtifactoryFS) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == 404 {
-		return nil, fs.ErrNotExist
-	} else if resp.StatusCode != 200 {
-		return nil, errors.New("unexpected status: " + resp.Status)
+	if afs.Cache != nil {
+		return afs.Cache.get(req, afs.do, afs.offline)
+	}
+	return afs.do(req)
+}
+
+// each run fn against every artifactory in turn until one of them can be
+// reached. When none of them can the cache is used no matter how old it is.
+func each[T any](afs ArtifactoryFS, name string, fn func(afs ArtifactoryFS, baseURL string) (T, error)) (T, error) {
+	var zero T
+	if len(afs.BaseURLs) == 0 {
+		return zero, errors.New("no artifactory urls")
+	}
+
+	var err error
+	for _, baseURL := range afs.BaseURLs {
+		var v T
+		v, err = fn(afs, baseURL)
+		if !IsUnavailable(err) {
+			return v, err
+		}
 	}
 
-	var meta struct {
-		Repo string `json:"repo"`
-		Path string `json:"path"`
-		Children []struct {
-			URI string `json:"uri"`
-			Folder bool `json:"folder"`
-		} `json:"children"`
-		Size string `json:"size"`
+	// nothing could be reached, whatever we have cached is better than nothing
+	if afs.Cache != nil && !afs.offline {
+		stale := afs
+		stale.offline = true
+		for _, baseURL := range afs.BaseURLs {
+			v, staleErr := fn(stale, baseURL)
+			if staleErr == nil {
+				slog.Warn("Unable to reach artifactory, using cached response", "name", name)
+				return v, nil
+			}
+		}
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+	return zero, err
+}
+
+func (afs ArtifactoryFS) Open(name string) (fs.File, error) {
+	return afs.OpenContext(context.Background(), name)
+}
+
+// OpenContext open a file or directory, files are streamed as they're read so
+// ctx must outlive the file
+func (afs ArtifactoryFS) OpenContext(ctx context.Context, name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{ Op: "open", Path: name, Err: fs.ErrInvalid }
+	}
+
+	return each(afs, name, func(afs ArtifactoryFS, baseURL string) (fs.File, error) {
+		return afs.openFrom(ctx, baseURL, name)
+	})
+}
+
+func (afs ArtifactoryFS) ReadFile(name string) ([]byte, error) {
+	return afs.ReadFileContext(context.Background(), name)
+}
+
+// ReadFileContext download the whole of a file
+func (afs ArtifactoryFS) ReadFileContext(ctx context.Context, name string) ([]byte, error) {
+	file, err := afs.OpenContext(ctx, name)
+	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	// It's a directory
-	if len(meta.Children) > 0 {
-		return &artifactoryDir{
-			entries: meta.Children,
-			pos: 0,
-			name: cleanName,
-		}, nil
+	return io.ReadAll(file)
+}
+
+// Stat get the information the storage api has about a file or folder, files
+// aren't downloaded
+func (afs ArtifactoryFS) Stat(name string) (fs.FileInfo, error) {
+	return afs.stat(context.Background(), name)
+}
+
+func (afs ArtifactoryFS) stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{ Op: "stat", Path: name, Err: fs.ErrInvalid }
+	}
+
+	return each(afs, name, func(afs ArtifactoryFS, baseURL string) (fs.FileInfo, error) {
+		info, err := afs.storage(ctx, baseURL, name)
+		if err != nil {
+			return nil, err
+		}
+		fi := info.fileInfo(baseURL + path.Clean(name))
+		fi.name = path.Base(path.Clean(name))
+		return fi, nil
+	})
+}
+
+// ReadDir list a folder with a single request, the entries are sorted by name
+func (afs ArtifactoryFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{ Op: "readdir", Path: name, Err: fs.ErrInvalid }
 	}
 
-	// It's a file
-	contentResp, err := afs.Client.Get(url)
+	return each(afs, name, func(afs ArtifactoryFS, baseURL string) ([]fs.DirEntry, error) {
+		info, err := afs.storage(context.Background(), baseURL, name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.isDir() {
+			return nil, &fs.PathError{ Op: "readdir", Path: name, Err: errors.New("not a directory") }
+		}
+
+		dir := &artifactoryDir{
+			entries: *info.Children,
+			name: path.Clean(name),
+			afs: afs,
+			ctx: context.Background(),
+		}
+		return dir.ReadDir(-1)
+	})
+}
+
+// readDirFS hides Glob so fs.Glob doesn't call back into it
+type readDirFS struct {
+	afs ArtifactoryFS
+}
+
+func (f readDirFS) Open(name string) (fs.File, error) { return f.afs.Open(name) }
+func (f readDirFS) ReadDir(name string) ([]fs.DirEntry, error) { return f.afs.ReadDir(name) }
+func (f readDirFS) Stat(name string) (fs.FileInfo, error) { return f.afs.Stat(name) }
+
+// Glob find every path matching pattern, each folder the pattern passes through
+// is listed with one request and nothing is downloaded
+func (afs ArtifactoryFS) Glob(pattern string) ([]string, error) {
+	return fs.Glob(readDirFS{ afs }, pattern)
+}
+
+type storageChild struct {
+	URI string `json:"uri"`
+	Folder bool `json:"folder"`
+}
+
+// storageInfo what the storage api says about a file or folder
+type storageInfo struct {
+	Repo string `json:"repo"`
+	Path string `json:"path"`
+	LastModified string `json:"lastModified"`
+	Size string `json:"size"`
+	Checksums Checksums `json:"checksums"`
+	// only folders have children, even when they're empty
+	Children *[]storageChild `json:"children"`
+}
+
+// storage fetch the storage api's information about a file or folder
+func (afs ArtifactoryFS) storage(ctx context.Context, baseURL string, name string) (*storageInfo, error) {
+	cleanName := path.Clean(name)
+
+	metaURL := baseURL + "api/storage/" + cleanName
+	if cleanName == "." {
+		metaURL = baseURL + "api/storage/"
+	}
+
+	resp, err := afs.get(ctx, metaURL)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	if contentResp.StatusCode == 404 {
+	if resp.StatusCode == 404 {
 		return nil, fs.ErrNotExist
-	} else if contentResp.StatusCode != 200 {
-		return nil, errors.New("unexpected status: " + contentResp.Status)
+	} else if resp.StatusCode != 200 {
+		return nil, errors.New("unexpected status: " + resp.Status)
 	}
 
-	data, err := io.ReadAll(contentResp.Body)
-	contentResp.Body.Close()
-	if err != nil {
+	var info storageInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
 		return nil, err
 	}
 
-	return &artifactoryFile{
-		data: bytes.NewReader(data),
-		name: cleanName,
-		size: int64(len(data)),
-	}, nil
+	if info.Children != nil {
+		slices.SortFunc(*info.Children, func(a, b storageChild) int {
+			return strings.Compare(a.URI, b.URI)
+		})
+	}
+
+	return &info, nil
 }
 
-type artifactoryFile struct {
-	data *bytes.Reader
-	name string
-	size int64
+func (info *storageInfo) isDir() bool {
+	return info.Children != nil
 }
 
-func (f *artifactoryFile) Stat() (fs.FileInfo, error) {
-	return &fileInfo{
-		name: path.Base(f.name),
-		size: f.size,
+// fileInfo turn the storage api's information into an fs.FileInfo, url is
+// where the file can be downloaded from
+func (info *storageInfo) fileInfo(url string) *fileInfo {
+	fi := &fileInfo{
+		name: path.Base(info.Path),
 		mode: 0444,
-	}, nil
-}
+	}
+
+	// artifactory uses RFC 3339 with milliseconds, which time.RFC3339 accepts
+	if t, err := time.Parse(time.RFC3339, info.LastModified); err == nil {
+		fi.modTime = t
+	}
+
+	if info.isDir() {
+		fi.mode = fs.ModeDir | 0555
+		return fi
+	}
 
-func (f *artifactoryFile) Read(p []byte) (int, error) {
-	return f.data.Read(p)
+	fi.size, _ = strconv.ParseInt(info.Size, 10, 64)
+	fi.meta = &FileMeta{ URL: url, Checksums: info.Checksums }
+	return fi
 }
 
-func (f *artifactoryFile) Close() error {
-	return nil
+func (afs ArtifactoryFS) openFrom(ctx context.Context, baseURL string, name string) (fs.File, error) {
+	cleanName := path.Clean(name)
+	url := baseURL + cleanName
+
+	meta, err := afs.storage(ctx, baseURL, cleanName)
+	if err != nil {
+		return nil, err
+	}
+
+	info := meta.fileInfo(url)
+	info.name = path.Base(cleanName)
+
+	// It's a directory
+	if meta.isDir() {
+		return &artifactoryDir{
+			entries: *meta.Children,
+			pos: 0,
+			name: cleanName,
+			info: info,
+			afs: afs,
+			ctx: ctx,
+		}, nil
+	}
+
+	// It's a file, small ones are cached so they're available offline
+	if afs.Cache != nil && meta.Size != "" && info.size <= maxCachedSize {
+		return afs.download(ctx, url, info)
+	}
+	if afs.offline {
+		return nil, errUnavailable{errors.New("not cached: " + url)}
+	}
+
+	return &artifactoryFile{
+		info: info,
+		ctx: ctx,
+		afs: afs,
+		url: url,
+	}, nil
 }
 
 type artifactoryDir struct {
-	entries []struct {
-		URI    string `json:"uri"`
-		Folder bool   `json:"folder"`
-	}
+	entries []storageChild
 	pos  int
 	name string
+	info *fileInfo
+	// used to stat entries when their info is asked for
+	afs ArtifactoryFS
+	ctx context.Context
 }
 
 func (d *artifactoryDir) Stat() (fs.FileInfo, error) {
-	return &fileInfo{
-		name: path.Base(d.name),
-		mode: fs.ModeDir | 0555,
-	}, nil
+	return d.info, nil
 }
 
 func (d *artifactoryDir) Read([]byte) (int, error) {
@@ -161,6 +469,9 @@ func (d *artifactoryDir) ReadDir(n int) ([]fs.DirEntry, error) {
 		entries = append(entries, &dirEntry{
 			name: strings.TrimPrefix(e.URI, "/"),
 			isDir: e.Folder,
+			path: path.Join(d.name, e.URI),
+			afs: d.afs,
+			ctx: d.ctx,
 		})
 	}
 
@@ -171,18 +482,31 @@ type fileInfo struct {
 	name string
 	size int64
 	mode fs.FileMode
+	modTime time.Time
+	meta *FileMeta
 }
 
 func (fi *fileInfo) Name() string { return fi.name }
 func (fi *fileInfo) Size() int64 { return fi.size }
 func (fi *fileInfo) Mode() fs.FileMode { return fi.mode }
-func (fi *fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
 func (fi *fileInfo) IsDir() bool { return fi.mode.IsDir() }
-func (fi *fileInfo) Sys() any { return nil }
+func (fi *fileInfo) Sys() any {
+	if fi.meta == nil {
+		return nil
+	}
+	return fi.meta
+}
 
 type dirEntry struct {
 	name  string
 	isDir bool
+	// where the entry is in the artifactory
+	path string
+	afs ArtifactoryFS
+	ctx context.Context
+	// filled in the first time Info is called
+	info fs.FileInfo
 }
 
 func (de *dirEntry) Name() string { return de.name }
@@ -194,9 +518,19 @@ func (de *dirEntry) Type() fs.FileMode {
 		return 0
 	}
 }
+
+// Info stat the entry, the directory listing doesn't include anything more than
+// the name so this costs a request the first time it's called
 func (de *dirEntry) Info() (fs.FileInfo, error) {
-	return &fileInfo{
-		name: de.name,
-		mode: de.Type(),
-	}, nil
+	if de.info != nil {
+		return de.info, nil
+	}
+
+	info, err := de.afs.stat(de.ctx, de.path)
+	if err != nil {
+		return nil, err
+	}
+
+	de.info = info
+	return info, nil
 }
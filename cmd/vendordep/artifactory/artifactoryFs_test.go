@@ -0,0 +1,528 @@
+package artifactory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testModTime = time.Date(2025, 1, 15, 12, 30, 0, 0, time.UTC)
+
+// fakeArtifactory answers the parts of the artifactory api rph uses from a map
+// of file paths to their contents
+type fakeArtifactory struct {
+	*httptest.Server
+	files map[string]string
+
+	// down answers every request with a 503
+	down atomic.Bool
+	// noSearch refuses every search like most public artifactories do
+	noSearch atomic.Bool
+	// noRanges ignores Range headers
+	noRanges atomic.Bool
+
+	mu sync.Mutex
+	// requests the number of requests for each path
+	requests map[string]int
+	// notModified the number of 304s sent
+	notModified int
+	// ranges every Range header which was sent
+	ranges []string
+}
+
+func newFakeArtifactory(t *testing.T, files map[string]string) *fakeArtifactory {
+	t.Helper()
+
+	f := &fakeArtifactory{ files: files, requests: map[string]int{} }
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// fs an ArtifactoryFS using just this artifactory, cached in a temporary
+// directory when ttl isn't negative
+func (f *fakeArtifactory) fs(t *testing.T, ttl time.Duration) ArtifactoryFS {
+	afs := New(f.URL)
+	if ttl >= 0 {
+		afs.Cache = NewCache(t.TempDir(), ttl)
+	}
+	return afs
+}
+
+func (f *fakeArtifactory) count(p string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[p]
+}
+
+func (f *fakeArtifactory) total() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, c := range f.requests {
+		n += c
+	}
+	return n
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// children the files and folders directly inside of dir, nil when dir isn't a
+// folder
+func (f *fakeArtifactory) children(dir string) []storageChild {
+	var children []storageChild
+	found := false
+	for p := range f.files {
+		rest, ok := strings.CutPrefix(p, dir + "/")
+		if dir == "" {
+			rest, ok = p, true
+		}
+		if !ok { continue }
+		found = true
+
+		name, _, folder := strings.Cut(rest, "/")
+		child := storageChild{ URI: "/" + name, Folder: folder }
+		if !slices.Contains(children, child) {
+			children = append(children, child)
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return children
+}
+
+func (f *fakeArtifactory) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests[r.URL.Path]++
+	if rng := r.Header.Get("Range"); rng != "" {
+		f.ranges = append(f.ranges, rng)
+	}
+	f.mu.Unlock()
+
+	if f.down.Load() {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+		return
+	}
+
+	if kind, ok := strings.CutPrefix(r.URL.Path, "/api/search/"); ok {
+		f.search(w, r, kind)
+		return
+	}
+
+	if p, ok := strings.CutPrefix(r.URL.Path, "/api/storage/"); ok {
+		f.storage(w, r, strings.Trim(p, "/"))
+		return
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, "/")
+	content, ok := f.files[p]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if f.noRanges.Load() {
+		r.Header.Del("Range")
+	}
+	w.Header().Set("ETag", `"` + checksum(content) + `"`)
+	http.ServeContent(w, r, path.Base(p), testModTime, strings.NewReader(content))
+}
+
+func (f *fakeArtifactory) storage(w http.ResponseWriter, r *http.Request, p string) {
+	repo, rest, _ := strings.Cut(p, "/")
+	info := storageInfo{
+		Repo: repo,
+		Path: "/" + rest,
+		LastModified: testModTime.Format("2006-01-02T15:04:05.000Z07:00"),
+	}
+
+	if content, ok := f.files[p]; ok {
+		info.Size = fmt.Sprint(len(content))
+		info.Checksums = Checksums{ Sha256: checksum(content) }
+	} else if children := f.children(p); children != nil {
+		info.Children = &children
+	} else {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, _ := json.Marshal(info)
+	etag := `"` + checksum(string(data)) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		f.mu.Lock()
+		f.notModified++
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// search answer the artifact, gavc and aql searches by going through every
+// file
+func (f *fakeArtifactory) search(w http.ResponseWriter, r *http.Request, kind string) {
+	if f.noSearch.Load() {
+		http.Error(w, "anonymous searches are not allowed", http.StatusForbidden)
+		return
+	}
+
+	var matches []string
+	query := r.URL.Query()
+	repos := strings.Split(query.Get("repos"), ",")
+	inRepos := func(p string) bool {
+		return query.Get("repos") == "" || slices.Contains(repos, strings.SplitN(p, "/", 2)[0])
+	}
+	glob := func(pattern, s string) bool {
+		ok, _ := path.Match(pattern, s)
+		return pattern == "" || ok
+	}
+
+	switch kind {
+	case "artifact":
+		for p := range f.files {
+			if inRepos(p) && glob(query.Get("name"), path.Base(p)) {
+				matches = append(matches, p)
+			}
+		}
+	case "gavc":
+		for p := range f.files {
+			// repo/group/as/dirs/artifactId/version/file
+			dirs := strings.Split(p, "/")
+			if len(dirs) < 5 || !inRepos(p) { continue }
+
+			n := len(dirs)
+			if glob(query.Get("g"), strings.Join(dirs[1:n - 3], ".")) &&
+				glob(query.Get("a"), dirs[n - 3]) && glob(query.Get("v"), dirs[n - 2]) {
+				matches = append(matches, p)
+			}
+		}
+	case "aql":
+		if r.Method != http.MethodPost {
+			http.Error(w, "aql must be posted", http.StatusMethodNotAllowed)
+			return
+		}
+		f.aql(w, r)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	slices.Sort(matches)
+	type result struct {
+		URI string `json:"uri"`
+	}
+	results := []result{}
+	for _, m := range matches {
+		results = append(results, result{ URI: "http://" + r.Host + "/api/storage/" + m })
+	}
+	json.NewEncoder(w).Encode(map[string][]result{ "results": results })
+}
+
+var (
+	aqlPathRe = regexp.MustCompile(`"path":("[^"]*")`)
+	aqlMatchRe = regexp.MustCompile(`"\$match":("[^"]*")`)
+)
+
+// aql answer the only kind of query rph sends, every file in a folder with a
+// name matching a pattern
+func (f *fakeArtifactory) aql(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	var dir, pattern string
+	if m := aqlPathRe.FindSubmatch(body); m != nil {
+		json.Unmarshal(m[1], &dir)
+	}
+	if m := aqlMatchRe.FindSubmatch(body); m != nil {
+		json.Unmarshal(m[1], &pattern)
+	}
+
+	type result struct {
+		Repo string `json:"repo"`
+		Path string `json:"path"`
+		Name string `json:"name"`
+		Size int `json:"size"`
+		Modified string `json:"modified"`
+	}
+	results := []result{}
+	for p, content := range f.files {
+		repo, rest, _ := strings.Cut(p, "/")
+		if path.Dir(rest) != dir { continue }
+		if ok, _ := path.Match(pattern, path.Base(p)); !ok { continue }
+
+		results = append(results, result{
+			Repo: repo,
+			Path: path.Dir(rest),
+			Name: path.Base(p),
+			Size: len(content),
+			Modified: testModTime.Format(time.RFC3339),
+		})
+	}
+	slices.SortFunc(results, func(a, b result) int { return strings.Compare(a.Name, b.Name) })
+
+	json.NewEncoder(w).Encode(map[string]any{ "results": results })
+}
+
+func TestReadFile(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/dir/a.json": `{"a":1}` })
+	afs := f.fs(t, -1)
+
+	data, err := afs.ReadFile("repo/dir/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":1}` {
+		t.Errorf("ReadFile = %s", data)
+	}
+
+	if _, err := afs.ReadFile("repo/dir/missing.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile of a missing file = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	down := newFakeArtifactory(t, nil)
+	down.down.Store(true)
+	up := newFakeArtifactory(t, map[string]string{ "repo/a.json": "up" })
+
+	afs := New(down.URL, up.URL)
+	data, err := afs.ReadFile("repo/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "up" {
+		t.Errorf("ReadFile = %s, want the second artifactory's file", data)
+	}
+	if down.total() == 0 {
+		t.Error("the first artifactory wasn't tried")
+	}
+
+	// a missing file is an answer, the next artifactory isn't asked
+	missing := newFakeArtifactory(t, nil)
+	afs = New(missing.URL, up.URL)
+	if _, err := afs.ReadFile("repo/a.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile = %v, want fs.ErrNotExist", err)
+	}
+
+	up.down.Store(true)
+	afs = New(down.URL, up.URL)
+	if _, err := afs.ReadFile("repo/a.json"); !IsUnavailable(err) {
+		t.Errorf("ReadFile with everything down = %v, want an unavailable error", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	up := newFakeArtifactory(t, map[string]string{ "repo/a.json": "up" })
+
+	afs := New(slow.URL, up.URL)
+	afs.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	data, err := afs.ReadFile("repo/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "up" {
+		t.Errorf("ReadFile = %s", data)
+	}
+	if time.Since(start) > 2 * time.Second {
+		t.Error("the slow artifactory wasn't given up on")
+	}
+}
+
+func TestStatFile(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/dir/a.json": `{"a":1}` })
+	afs := f.fs(t, -1)
+
+	info, err := afs.Stat("repo/dir/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name() != "a.json" || info.Size() != 7 || info.IsDir() || !info.ModTime().Equal(testModTime) {
+		t.Errorf("Stat = %s %d %v %s", info.Name(), info.Size(), info.IsDir(), info.ModTime())
+	}
+	if f.count("/repo/dir/a.json") != 0 {
+		t.Error("Stat downloaded the file")
+	}
+
+	meta, ok := info.Sys().(*FileMeta)
+	if !ok {
+		t.Fatalf("Sys = %T, want *FileMeta", info.Sys())
+	}
+	if meta.URL != f.URL + "/repo/dir/a.json" {
+		t.Errorf("URL = %s", meta.URL)
+	}
+	if meta.Checksums.Sha256 != checksum(`{"a":1}`) {
+		t.Errorf("Checksums = %+v", meta.Checksums)
+	}
+	if err := meta.Verify([]byte(`{"a":1}`)); err != nil {
+		t.Errorf("Verify of the right data failed: %v", err)
+	}
+	if err := meta.Verify([]byte(`{"a":2}`)); err == nil {
+		t.Error("Verify of the wrong data passed")
+	}
+
+	if _, err := afs.Stat("repo/dir/missing.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a missing file = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestStatDir(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/dir/a.json": "a" })
+	afs := f.fs(t, -1)
+
+	info, err := afs.Stat("repo/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name() != "dir" || !info.IsDir() || info.Sys() != nil {
+		t.Errorf("Stat = %s %v %v", info.Name(), info.IsDir(), info.Sys())
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	data := []byte("data")
+	tests := []struct {
+		checksums Checksums
+		ok bool
+	}{
+		{ Checksums{}, true },
+		{ Checksums{ Sha256: checksum("data") }, true },
+		{ Checksums{ Sha256: strings.ToUpper(checksum("data")) }, true },
+		{ Checksums{ Sha1: "a17c9aaa61e80a1bf71d0d850af4e5baa9800bbd" }, true },
+		{ Checksums{ Md5: "8d777f385d3dfec8815d20f7496026dc" }, true },
+		{ Checksums{ Md5: "00000000000000000000000000000000" }, false },
+		// the strongest checksum is the one which counts
+		{ Checksums{ Sha256: checksum("other"), Md5: "8d777f385d3dfec8815d20f7496026dc" }, false },
+	}
+
+	for _, tt := range tests {
+		meta := FileMeta{ URL: "x", Checksums: tt.checksums }
+		if err := meta.Verify(data); (err == nil) != tt.ok {
+			t.Errorf("Verify with %+v = %v", tt.checksums, err)
+		}
+	}
+}
+
+var testTree = map[string]string{
+	"vendordeps/vendordep-marketplace/2025/REVLib-2025.0.2.json": "{}",
+	"vendordeps/vendordep-marketplace/2025/REVLib-2025.0.3.json": "{}",
+	"vendordeps/vendordep-marketplace/2025/photonlib-v2025.3.1.json": "{}",
+	"vendordeps/vendordep-marketplace/2024/REVLib-2024.2.4.json": "{}",
+	"maven/com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar": "jar",
+}
+
+func TestReadDir(t *testing.T) {
+	f := newFakeArtifactory(t, testTree)
+	afs := f.fs(t, -1)
+
+	entries, err := afs.ReadDir("vendordeps/vendordep-marketplace/2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			t.Errorf("%s is a file but says it's a directory", e.Name())
+		}
+		names = append(names, e.Name())
+	}
+	want := []string{ "REVLib-2025.0.2.json", "REVLib-2025.0.3.json", "photonlib-v2025.3.1.json" }
+	if !slices.Equal(names, want) {
+		t.Errorf("ReadDir = %v, want %v", names, want)
+	}
+	if n := f.total(); n != 1 {
+		t.Errorf("ReadDir took %d requests, want 1", n)
+	}
+
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name() != "REVLib-2025.0.2.json" || info.Size() != 2 {
+		t.Errorf("Info = %s %d", info.Name(), info.Size())
+	}
+
+	entries, err = afs.ReadDir("vendordeps/vendordep-marketplace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !entries[0].IsDir() || entries[0].Type() != fs.ModeDir {
+		t.Errorf("ReadDir of the years = %v", entries)
+	}
+
+	if _, err := afs.ReadDir("vendordeps/vendordep-marketplace/2025/REVLib-2025.0.2.json"); err == nil {
+		t.Error("ReadDir of a file should fail")
+	}
+	if _, err := afs.ReadDir("vendordeps/nope"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadDir of a missing folder = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	f := newFakeArtifactory(t, testTree)
+	afs := f.fs(t, -1)
+
+	matches, err := fs.Glob(afs, "vendordeps/vendordep-marketplace/*/REVLib-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"vendordeps/vendordep-marketplace/2024/REVLib-2024.2.4.json",
+		"vendordeps/vendordep-marketplace/2025/REVLib-2025.0.2.json",
+		"vendordeps/vendordep-marketplace/2025/REVLib-2025.0.3.json",
+	}
+	if !slices.Equal(matches, want) {
+		t.Errorf("Glob = %v, want %v", matches, want)
+	}
+
+	// one listing for the marketplace and one for each year, nothing is
+	// downloaded
+	if n := f.total(); n != 3 {
+		t.Errorf("Glob took %d requests, want 3", n)
+	}
+}
+
+func TestFS(t *testing.T) {
+	f := newFakeArtifactory(t, testTree)
+
+	var files []string
+	for p := range testTree {
+		files = append(files, p)
+	}
+	if err := fstest.TestFS(f.fs(t, time.Hour), files...); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,189 @@
+package artifactory
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL how long a cached response is used before it's checked with
+// the artifactory again
+const DefaultCacheTTL = time.Hour
+
+// Cache keeps artifactory responses on disk so they can be reused without
+// talking to the artifactory, and so there's something to fall back on when it
+// can't be reached. Responses older than TTL are revalidated with the ETag or
+// Last-Modified the artifactory gave us.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// cacheEntry what's stored next to a cached response body
+type cacheEntry struct {
+	URL string `json:"url"`
+	ETag string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Fetched time.Time `json:"fetched"`
+}
+
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{ Dir: dir, TTL: ttl }
+}
+
+// path where the entry for url is kept, the body is kept beside it with a
+// .body extension
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *Cache) load(url string) (*cacheEntry, []byte, error) {
+	path := c.path(url)
+
+	data, err := os.ReadFile(path + ".json")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil, err
+	}
+	// it'd take a sha256 collision but make sure anyway
+	if entry.URL != url {
+		return nil, nil, os.ErrNotExist
+	}
+
+	body, err := os.ReadFile(path + ".body")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &entry, body, nil
+}
+
+// writeFile write a file so nothing reading the cache at the same time ever
+// sees half of it
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path) + ".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *Cache) store(entry *cacheEntry, body []byte) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// the body goes first so an entry never points at a body which isn't there
+	path := c.path(entry.URL)
+	if body != nil {
+		if err := writeFile(path + ".body", body); err != nil {
+			return err
+		}
+	}
+	return writeFile(path + ".json", data)
+}
+
+// Clear remove every cached response
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// cachedResponse turn a cached body back into a response
+func cachedResponse(body []byte) *http.Response {
+	return &http.Response{
+		Status: "200 OK",
+		StatusCode: http.StatusOK,
+		Header: http.Header{},
+		Body: io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
+
+// get send req through the cache. Fresh responses are returned without making
+// a request at all, stale ones are revalidated. When stale is set the network
+// isn't touched and whatever is cached is used no matter how old it is.
+func (c *Cache) get(req *http.Request, do func(*http.Request) (*http.Response, error), stale bool) (*http.Response, error) {
+	url := req.URL.String()
+
+	entry, body, err := c.load(url)
+	if err != nil {
+		entry = nil
+	}
+
+	if entry != nil && (stale || time.Since(entry.Fetched) < c.TTL) {
+		return cachedResponse(body), nil
+	}
+	if stale {
+		return nil, errUnavailable{errors.New("not cached: " + url)}
+	}
+
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		entry.Fetched = time.Now()
+		c.store(entry, nil)
+		return cachedResponse(body), nil
+	}
+
+	// only successful responses are worth keeping, everything else is handed
+	// back as is
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, errUnavailable{err}
+	}
+
+	c.store(&cacheEntry{
+		URL: url,
+		ETag: resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched: time.Now(),
+	}, data)
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
@@ -0,0 +1,138 @@
+package artifactory
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCacheFreshResponses(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/a.json": "a" })
+	afs := f.fs(t, time.Hour)
+
+	for range 3 {
+		data, err := afs.ReadFile("repo/a.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "a" {
+			t.Fatalf("ReadFile = %s", data)
+		}
+	}
+
+	if n := f.count("/api/storage/repo/a.json"); n != 1 {
+		t.Errorf("storage api was asked %d times, fresh responses shouldn't be asked for again", n)
+	}
+	if n := f.count("/repo/a.json"); n != 1 {
+		t.Errorf("file was downloaded %d times, fresh responses shouldn't be asked for again", n)
+	}
+}
+
+func TestCacheRevalidates(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/a.json": "a" })
+	afs := f.fs(t, 0)
+
+	for range 2 {
+		if _, err := afs.ReadFile("repo/a.json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := f.count("/api/storage/repo/a.json"); n != 2 {
+		t.Errorf("storage api was asked %d times, stale responses should be checked again", n)
+	}
+	if f.notModified == 0 {
+		t.Error("stale responses weren't revalidated with their etag")
+	}
+
+	// a changed file is downloaded again
+	f.files["repo/a.json"] = "changed"
+	data, err := afs.ReadFile("repo/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "changed" {
+		t.Errorf("ReadFile = %s, want the changed file", data)
+	}
+}
+
+func TestCacheUsedWhenUnavailable(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/a.json": "a", "repo/b.json": "b" })
+	afs := f.fs(t, 0)
+
+	if _, err := afs.ReadFile("repo/a.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	f.down.Store(true)
+
+	data, err := afs.ReadFile("repo/a.json")
+	if err != nil {
+		t.Fatalf("ReadFile didn't fall back on the cache: %v", err)
+	}
+	if string(data) != "a" {
+		t.Errorf("ReadFile = %s", data)
+	}
+
+	if _, err := afs.ReadFile("repo/b.json"); !IsUnavailable(err) {
+		t.Errorf("ReadFile of something never cached = %v, want an unavailable error", err)
+	}
+}
+
+func TestCacheIgnoresErrors(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{})
+	afs := f.fs(t, time.Hour)
+
+	for range 2 {
+		if _, err := afs.ReadFile("repo/a.json"); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("ReadFile = %v, want os.ErrNotExist", err)
+		}
+	}
+	if n := f.count("/api/storage/repo/a.json"); n != 2 {
+		t.Errorf("storage api was asked %d times, missing files shouldn't be cached", n)
+	}
+
+	f.files["repo/a.json"] = "a"
+	if _, err := afs.ReadFile("repo/a.json"); err != nil {
+		t.Errorf("ReadFile of a file which was added = %v", err)
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/a.json": "a" })
+	afs := f.fs(t, time.Hour)
+
+	if _, err := afs.ReadFile("repo/a.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := afs.Cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(afs.Cache.Dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("cache directory is still there: %v", err)
+	}
+
+	if _, err := afs.ReadFile("repo/a.json"); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.count("/repo/a.json"); n != 2 {
+		t.Errorf("file was downloaded %d times, it should be downloaded again after a clear", n)
+	}
+}
+
+func TestCacheKeepsUrlsApart(t *testing.T) {
+	c := NewCache(t.TempDir(), time.Hour)
+	entry := &cacheEntry{ URL: "http://a/x", Fetched: time.Now() }
+	if err := c.store(entry, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, body, err := c.load("http://a/x")
+	if err != nil || string(body) != "x" {
+		t.Errorf("load = %s, %v", body, err)
+	}
+	if _, _, err := c.load("http://a/y"); err == nil {
+		t.Error("load found an entry for a different url")
+	}
+}
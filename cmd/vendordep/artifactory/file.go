@@ -0,0 +1,163 @@
+package artifactory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+)
+
+// artifactoryFile a file on an artifactory. Small files are downloaded in one go,
+// everything else is streamed and only requested once it's read.
+type artifactoryFile struct {
+	info *fileInfo
+	// the whole file, when it was small enough to download up front
+	data *bytes.Reader
+
+	ctx context.Context
+	afs ArtifactoryFS
+	url string
+	// the response being read from, nil until the first read and after a seek
+	body io.ReadCloser
+	offset int64
+	closed bool
+}
+
+// download fetch the whole of a file through the cache
+func (afs ArtifactoryFS) download(ctx context.Context, url string, info *fileInfo) (*artifactoryFile, error) {
+	resp, err := afs.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, fs.ErrNotExist
+	} else if resp.StatusCode != 200 {
+		return nil, errors.New("unexpected status: " + resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// what we actually got is more trustworthy than what we were told we'd get
+	info.size = int64(len(data))
+
+	return &artifactoryFile{
+		info: info,
+		data: bytes.NewReader(data),
+		url: url,
+	}, nil
+}
+
+func (f *artifactoryFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// open request the file from where we're up to
+func (f *artifactoryFile) open() error {
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return err
+	}
+	if f.offset > 0 {
+		req.Header.Set("Range", "bytes=" + strconv.FormatInt(f.offset, 10) + "-")
+	}
+
+	resp, err := f.afs.do(req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// the artifactory ignored the range so skip to where we should be
+		if f.offset > 0 {
+			if _, err := io.CopyN(io.Discard, resp.Body, f.offset); err != nil {
+				resp.Body.Close()
+				return err
+			}
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		return io.EOF
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return fs.ErrNotExist
+	default:
+		resp.Body.Close()
+		return errors.New("unexpected status: " + resp.Status)
+	}
+
+	f.body = resp.Body
+	return nil
+}
+
+func (f *artifactoryFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if f.data != nil {
+		return f.data.Read(p)
+	}
+
+	if f.body == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+// Seek move to somewhere else in the file, for streamed files this is free
+// until the next read which asks the artifactory for just the part we want
+func (f *artifactoryFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if f.data != nil {
+		return f.data.Seek(offset, whence)
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *artifactoryFile) Close() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.closed = true
+
+	if f.body != nil {
+		return f.body.Close()
+	}
+	return nil
+}
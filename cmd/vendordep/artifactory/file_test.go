@@ -0,0 +1,175 @@
+package artifactory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bigFile something too big to be cached so it gets streamed
+func bigFile() string {
+	var b strings.Builder
+	for i := 0; b.Len() <= maxCachedSize; i++ {
+		b.WriteByte(byte(i % 251))
+	}
+	return b.String()
+}
+
+func TestStreamedFile(t *testing.T) {
+	content := bigFile()
+	f := newFakeArtifactory(t, map[string]string{ "maven/lib.zip": content })
+	afs := f.fs(t, time.Hour)
+
+	file, err := afs.Open("maven/lib.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if f.count("/maven/lib.zip") != 0 {
+		t.Error("the file was downloaded before it was read")
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("read %d bytes which don't match the %d byte file", len(data), len(content))
+	}
+
+	// big files aren't kept
+	if _, err := afs.ReadFile("maven/lib.zip"); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.count("/maven/lib.zip"); n != 2 {
+		t.Errorf("file was downloaded %d times, big files shouldn't be cached", n)
+	}
+}
+
+func TestStreamedFileSeek(t *testing.T) {
+	content := bigFile()
+
+	for _, ranges := range []bool{ true, false } {
+		f := newFakeArtifactory(t, map[string]string{ "maven/lib.zip": content })
+		f.noRanges.Store(!ranges)
+		afs := f.fs(t, -1)
+
+		file, err := afs.Open("maven/lib.zip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		seeker := file.(io.ReadSeeker)
+
+		check := func(offset int64, whence int, want int64) {
+			t.Helper()
+
+			pos, err := seeker.Seek(offset, whence)
+			if err != nil || pos != want {
+				t.Fatalf("Seek(%d, %d) = %d, %v, want %d", offset, whence, pos, err, want)
+			}
+
+			buf := make([]byte, 16)
+			n, err := io.ReadFull(seeker, buf)
+			if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("read after Seek(%d, %d) failed: %v", offset, whence, err)
+			}
+			if string(buf[:n]) != content[want:min(want + 16, int64(len(content)))] {
+				t.Errorf("read after Seek(%d, %d) got the wrong bytes (ranges %v)", offset, whence, ranges)
+			}
+		}
+
+		check(1000, io.SeekStart, 1000)
+		check(100, io.SeekCurrent, 1116)
+		check(-10, io.SeekEnd, int64(len(content)) - 10)
+		check(0, io.SeekStart, 0)
+
+		if ranges && len(f.ranges) == 0 {
+			t.Error("seeking didn't ask for a range")
+		}
+
+		if _, err := seeker.Seek(-1, io.SeekStart); err == nil {
+			t.Error("seeking before the start should fail")
+		}
+		if _, err := seeker.Seek(int64(len(content)) + 10, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := seeker.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("read past the end = %v, want io.EOF", err)
+		}
+
+		file.Close()
+		if _, err := seeker.Read(make([]byte, 1)); !errors.Is(err, fs.ErrClosed) {
+			t.Errorf("read after Close = %v, want fs.ErrClosed", err)
+		}
+	}
+}
+
+func TestSmallFileSeek(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "repo/a.json": "0123456789" })
+	afs := f.fs(t, time.Hour)
+
+	file, err := afs.Open("repo/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	seeker := file.(io.ReadSeeker)
+	if _, err := seeker.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(seeker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "456789" {
+		t.Errorf("read after seek = %s", data)
+	}
+	if len(f.ranges) != 0 {
+		t.Error("a small file which was already downloaded asked for a range")
+	}
+}
+
+func TestStreamedFileContext(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "maven/lib.zip": bigFile() })
+	afs := f.fs(t, -1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	file, err := afs.OpenContext(ctx, "maven/lib.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	cancel()
+	if _, err := io.ReadAll(file); !errors.Is(err, context.Canceled) {
+		t.Errorf("read after the context was cancelled = %v, want context.Canceled", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := afs.OpenContext(ctx, "maven/lib.zip"); !errors.Is(err, context.Canceled) {
+		t.Errorf("open with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestStreamedFileOffline(t *testing.T) {
+	f := newFakeArtifactory(t, map[string]string{ "maven/lib.zip": bigFile() })
+	afs := f.fs(t, 0)
+
+	if _, err := afs.Stat("maven/lib.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the storage api response is cached but there's no way to stream the
+	// file without the artifactory
+	f.down.Store(true)
+	if _, err := afs.Open("maven/lib.zip"); !IsUnavailable(err) {
+		t.Errorf("Open = %v, want an unavailable error", err)
+	}
+}
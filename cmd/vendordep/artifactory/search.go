@@ -0,0 +1,177 @@
+package artifactory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrSearchUnavailable the artifactory doesn't have the search api, or won't let
+// us use it. Anything searching should fall back on walking the artifactory.
+var ErrSearchUnavailable = errors.New("search is not available on this artifactory")
+
+// SearchResult a file found by searching the artifactory
+type SearchResult struct {
+	// Path where the file is, this can be passed to Open
+	Path string
+	// Size and Modified are only known for AQL searches
+	Size int64
+	Modified time.Time
+}
+
+// Repo the repository the file is in
+func (r SearchResult) Repo() string {
+	return strings.SplitN(r.Path, "/", 2)[0]
+}
+
+func (r SearchResult) Name() string {
+	return path.Base(r.Path)
+}
+
+// searchStatus turn the status of a search into an error, artifactories which
+// don't allow anonymous searches or don't have the endpoint at all are treated
+// the same
+func searchStatus(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed:
+		return ErrSearchUnavailable
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return errors.New("search failed: " + resp.Status + ": " + strings.TrimSpace(string(msg)))
+}
+
+// uriSearch run one of the searches which answer with a list of storage api
+// uris
+func (afs ArtifactoryFS) uriSearch(ctx context.Context, endpoint string, query url.Values) ([]SearchResult, error) {
+	return each(afs, endpoint, func(afs ArtifactoryFS, baseURL string) ([]SearchResult, error) {
+		resp, err := afs.get(ctx, baseURL + "api/search/" + endpoint + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if err := searchStatus(resp); err != nil {
+			return nil, err
+		}
+
+		var body struct {
+			Results []struct {
+				URI string `json:"uri"`
+			} `json:"results"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+
+		// the uris point at the storage api, possibly on a different host to
+		// the one we asked if the artifactory is behind a proxy
+		results := make([]SearchResult, 0, len(body.Results))
+		for _, r := range body.Results {
+			_, p, ok := strings.Cut(r.URI, "/api/storage/")
+			if !ok { continue }
+			if unescaped, err := url.PathUnescape(p); err == nil {
+				p = unescaped
+			}
+			results = append(results, SearchResult{ Path: p })
+		}
+		return results, nil
+	})
+}
+
+// SearchArtifact find files by name, the name may use * and ? wildcards. When
+// repos are given only they are searched.
+func (afs ArtifactoryFS) SearchArtifact(ctx context.Context, name string, repos ...string) ([]SearchResult, error) {
+	query := url.Values{}
+	query.Set("name", name)
+	if len(repos) > 0 {
+		query.Set("repos", strings.Join(repos, ","))
+	}
+
+	return afs.uriSearch(ctx, "artifact", query)
+}
+
+// SearchGAVC find maven artifacts by their coordinates, any of which may be
+// left empty to match everything
+func (afs ArtifactoryFS) SearchGAVC(ctx context.Context, groupId, artifactId, version, classifier string, repos ...string) ([]SearchResult, error) {
+	query := url.Values{}
+	for k, v := range map[string]string{ "g": groupId, "a": artifactId, "v": version, "c": classifier } {
+		if v != "" {
+			query.Set(k, v)
+		}
+	}
+	if len(repos) > 0 {
+		query.Set("repos", strings.Join(repos, ","))
+	}
+
+	return afs.uriSearch(ctx, "gavc", query)
+}
+
+// AQL run an artifactory query language query. Only items.find queries are
+// understood, and they must include repo, path and name.
+//
+//	items.find({"repo":"vendordeps","name":{"$match":"REVLib-*.json"}}).include("repo","path","name","size","modified")
+func (afs ArtifactoryFS) AQL(ctx context.Context, query string) ([]SearchResult, error) {
+	return each(afs, "aql", func(afs ArtifactoryFS, baseURL string) ([]SearchResult, error) {
+		// queries are posted so there's nothing cached to fall back on
+		if afs.offline {
+			return nil, errUnavailable{errors.New("not cached: aql")}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL + "api/search/aql", strings.NewReader(query))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "text/plain")
+
+		resp, err := afs.do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if err := searchStatus(resp); err != nil {
+			return nil, err
+		}
+
+		var body struct {
+			Results []struct {
+				Repo string `json:"repo"`
+				Path string `json:"path"`
+				Name string `json:"name"`
+				Size int64 `json:"size"`
+				Modified string `json:"modified"`
+			} `json:"results"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+
+		results := make([]SearchResult, 0, len(body.Results))
+		for _, r := range body.Results {
+			result := SearchResult{
+				Path: path.Join(r.Repo, r.Path, r.Name),
+				Size: r.Size,
+			}
+			if t, err := time.Parse(time.RFC3339, r.Modified); err == nil {
+				result.Modified = t
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	})
+}
+
+// AQLString quote s so it can be used in an AQL query
+func AQLString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,130 @@
+package artifactory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func resultPaths(results []SearchResult) []string {
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+	return paths
+}
+
+func TestSearchArtifact(t *testing.T) {
+	f := newFakeArtifactory(t, testTree)
+	afs := f.fs(t, -1)
+
+	results, err := afs.SearchArtifact(context.Background(), "REVLib-2025*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"vendordeps/vendordep-marketplace/2025/REVLib-2025.0.2.json",
+		"vendordeps/vendordep-marketplace/2025/REVLib-2025.0.3.json",
+	}
+	if got := resultPaths(results); !slices.Equal(got, want) {
+		t.Errorf("SearchArtifact = %v, want %v", got, want)
+	}
+	if results[0].Repo() != "vendordeps" || results[0].Name() != "REVLib-2025.0.2.json" {
+		t.Errorf("result is in %s named %s", results[0].Repo(), results[0].Name())
+	}
+
+	results, err = afs.SearchArtifact(context.Background(), "REVLib*", "maven")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{ "maven/com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar" }
+	if got := resultPaths(results); !slices.Equal(got, want) {
+		t.Errorf("SearchArtifact in maven = %v, want %v", got, want)
+	}
+}
+
+func TestSearchGAVC(t *testing.T) {
+	f := newFakeArtifactory(t, testTree)
+	afs := f.fs(t, -1)
+
+	want := []string{ "maven/com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar" }
+
+	for _, g := range []string{ "com.revrobotics.frc", "com.revrobotics*" } {
+		results, err := afs.SearchGAVC(context.Background(), g, "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resultPaths(results); !slices.Equal(got, want) {
+			t.Errorf("SearchGAVC(%s) = %v, want %v", g, got, want)
+		}
+	}
+
+	results, err := afs.SearchGAVC(context.Background(), "com.revrobotics", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Errorf("an exact group shouldn't match groups under it: %v", resultPaths(results))
+	}
+}
+
+func TestAQL(t *testing.T) {
+	f := newFakeArtifactory(t, testTree)
+	afs := f.fs(t, -1)
+
+	query := fmt.Sprintf(`items.find({"repo":"vendordeps","path":"vendordep-marketplace/2025","name":{"$match":%s}}).include("repo","path","name","size","modified")`,
+		AQLString("photonlib-*.json"))
+	results, err := afs.AQL(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("AQL = %v", resultPaths(results))
+	}
+	r := results[0]
+	if r.Path != "vendordeps/vendordep-marketplace/2025/photonlib-v2025.3.1.json" || r.Size != 2 || !r.Modified.Equal(testModTime) {
+		t.Errorf("AQL = %s %d %s", r.Path, r.Size, r.Modified)
+	}
+}
+
+func TestSearchUnavailable(t *testing.T) {
+	f := newFakeArtifactory(t, testTree)
+	f.noSearch.Store(true)
+	afs := f.fs(t, time.Hour)
+
+	ctx := context.Background()
+	if _, err := afs.SearchArtifact(ctx, "*.json"); !errors.Is(err, ErrSearchUnavailable) {
+		t.Errorf("SearchArtifact = %v, want ErrSearchUnavailable", err)
+	}
+	if _, err := afs.SearchGAVC(ctx, "com.revrobotics*", "", "", ""); !errors.Is(err, ErrSearchUnavailable) {
+		t.Errorf("SearchGAVC = %v, want ErrSearchUnavailable", err)
+	}
+	if _, err := afs.AQL(ctx, `items.find({})`); !errors.Is(err, ErrSearchUnavailable) {
+		t.Errorf("AQL = %v, want ErrSearchUnavailable", err)
+	}
+
+	// nothing answers AQL when the artifactory can't be reached
+	f.noSearch.Store(false)
+	f.down.Store(true)
+	if _, err := afs.AQL(ctx, `items.find({})`); !IsUnavailable(err) {
+		t.Errorf("AQL with the artifactory down = %v, want an unavailable error", err)
+	}
+}
+
+func TestAQLString(t *testing.T) {
+	tests := map[string]string{
+		"REVLib-*.json": `"REVLib-*.json"`,
+		`a "quoted" name`: `"a \"quoted\" name"`,
+		`back\slash`: `"back\\slash"`,
+	}
+
+	for in, want := range tests {
+		if got := AQLString(in); got != want {
+			t.Errorf("AQLString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package vendordep
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+const RoboRioPlatform = "linuxathena"
+
+// Artifact a single file in a maven repository
+type Artifact struct {
+	GroupId string
+	ArtifactId string
+	Version string
+	Classifier string
+	Extension string
+	// Optional artifacts may be missing from the repository without it being an
+	// error, e.g. poms.
+	Optional bool
+}
+
+// Path the path to the artifact relative to the root of a maven repository
+func (a Artifact) Path() string {
+	name := a.ArtifactId + "-" + a.Version
+	if a.Classifier != "" {
+		name += "-" + a.Classifier
+	}
+	name += "." + a.Extension
+
+	return path.Join(strings.ReplaceAll(a.GroupId, ".", "/"), a.ArtifactId, a.Version, name)
+}
+
+func (a Artifact) String() string {
+	coords := a.GroupId + ":" + a.ArtifactId + ":" + a.Version
+	if a.Classifier != "" {
+		coords += ":" + a.Classifier
+	}
+	return coords + "@" + a.Extension
+}
+
+// HostPlatform get the wpilib name of the platform rph is running on
+func HostPlatform() string {
+	switch runtime.GOOS {
+	case "windows":
+		if runtime.GOARCH == "arm64" {
+			return "windowsarm64"
+		}
+		return "windowsx86-64"
+	case "darwin":
+		return "osxuniversal"
+	default:
+		switch runtime.GOARCH {
+		case "arm64":
+			return "linuxarm64"
+		case "arm":
+			return "linuxarm32"
+		default:
+			return "linuxx86-64"
+		}
+	}
+}
+
+// Artifacts resolve every maven artifact gradle would need to build the
+// vendordep for the given platforms. When debug is set the debug variants of
+// native libraries are used.
+func (v *Vendordep) Artifacts(platforms []string, debug bool) []Artifact {
+	var artifacts []Artifact
+	seen := map[string]bool{}
+
+	add := func(a Artifact) {
+		if seen[a.String()] { return }
+		seen[a.String()] = true
+		artifacts = append(artifacts, a)
+	}
+
+	pom := func(groupId, artifactId, version string) {
+		add(Artifact{ GroupId: groupId, ArtifactId: artifactId, Version: version, Extension: "pom", Optional: true })
+	}
+
+	suffix := ""
+	if debug {
+		suffix = "debug"
+	}
+
+	for _, d := range v.JavaDependencies {
+		pom(d.GroupId, d.ArtifactId, d.Version)
+		add(Artifact{ GroupId: d.GroupId, ArtifactId: d.ArtifactId, Version: d.Version, Extension: "jar" })
+	}
+
+	for _, d := range v.JniDependencies {
+		pom(d.GroupId, d.ArtifactId, d.Version)
+
+		ext := "zip"
+		if d.IsJar {
+			ext = "jar"
+		}
+
+		for _, p := range platforms {
+			if !slices.Contains(d.ValidPlatforms, p) { continue }
+			add(Artifact{ GroupId: d.GroupId, ArtifactId: d.ArtifactId, Version: d.Version, Classifier: p + suffix, Extension: ext })
+		}
+	}
+
+	for _, d := range v.CppDependencies {
+		pom(d.GroupId, d.ArtifactId, d.Version)
+
+		if d.HeaderClassifier != "" {
+			add(Artifact{ GroupId: d.GroupId, ArtifactId: d.ArtifactId, Version: d.Version, Classifier: d.HeaderClassifier, Extension: "zip" })
+		}
+
+		linkage := ""
+		if !d.SharedLibrary {
+			linkage = "static"
+		}
+
+		for _, p := range platforms {
+			if !slices.Contains(d.BinaryPlatforms, p) { continue }
+			add(Artifact{ GroupId: d.GroupId, ArtifactId: d.ArtifactId, Version: d.Version, Classifier: p + linkage + suffix, Extension: "zip" })
+		}
+	}
+
+	return artifacts
+}
+
+// FetchArtifact download an artifact from the first maven repository which has
+// it into the local maven repository at repoDir. Artifacts which have already
+// been downloaded are skipped unless force is set.
+func FetchArtifact(repoDir string, mavenUrls []string, a Artifact, force bool) error {
+	out := filepath.Join(repoDir, filepath.FromSlash(a.Path()))
+	if _, err := os.Stat(out); err == nil && !force {
+		slog.Debug("Artifact already downloaded", "artifact", a)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+
+	var lastErr error = errors.New("no maven urls")
+	for _, mavenUrl := range mavenUrls {
+		url := strings.TrimRight(mavenUrl, "/") + "/" + a.Path()
+
+		err := downloadTo(url, out)
+		if err == nil {
+			return nil
+		}
+
+		slog.Debug("Artifact not found in maven repository", "url", url, "error", err)
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+// FetchArtifacts download artifacts into the local maven repository at repoDir,
+// returning the artifacts which couldn't be fetched. Optional artifacts which
+// are missing are left out.
+func FetchArtifacts(repoDir string, mavenUrls []string, artifacts []Artifact, force bool) []Artifact {
+	var missing []Artifact
+	for _, a := range artifacts {
+		err := FetchArtifact(repoDir, mavenUrls, a, force)
+		if err == nil { continue }
+
+		if a.Optional {
+			slog.Debug("Optional artifact not found", "artifact", a, "error", err)
+		} else {
+			slog.Error("Failed to fetch artifact", "artifact", a, "error", err)
+			missing = append(missing, a)
+		}
+	}
+	return missing
+}
+
+// MissingArtifactsError the error for artifacts which couldn't be fetched, when
+// allowMissing is set they're only warned about
+func MissingArtifactsError(missing []Artifact, allowMissing bool) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	if allowMissing {
+		slog.Warn("Some artifacts could not be fetched, offline builds may fail", "failed", len(missing))
+		return nil
+	}
+	return fmt.Errorf("unable to fetch %d artifacts", len(missing))
+}
+
+// GradleRepoSnippet the gradle needed to use a local maven repository
+func GradleRepoSnippet(repoDir string) string {
+	path := filepath.ToSlash(repoDir)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{ Scheme: "file", Path: path }
+
+	return fmt.Sprintf("maven { url = uri(\"%s\") }", u.String())
+}
+
+// GradleInitScript a gradle init script which adds a local maven repository to
+// every project
+func GradleInitScript(repoDir string) string {
+	return "allprojects {\n  repositories {\n    " + GradleRepoSnippet(repoDir) + "\n  }\n}\n"
+}
+
+// downloadTo download a file without leaving a partial file behind on failure
+func downloadTo(url string, out string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, resp.Body)
+	tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), out)
+}
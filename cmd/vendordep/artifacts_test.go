@@ -0,0 +1,249 @@
+package vendordep
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func artifactPaths(artifacts []Artifact) []string {
+	var paths []string
+	for _, a := range artifacts {
+		paths = append(paths, a.Path())
+	}
+	return paths
+}
+
+func TestArtifacts(t *testing.T) {
+	tests := []struct {
+		name string
+		dep Vendordep
+		platforms []string
+		debug bool
+		want []string
+	}{
+		{
+			name: "java",
+			dep: Vendordep{ JavaDependencies: []JavaDepedency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-java", Version: "2025.0.3" },
+			} },
+			platforms: []string{ RoboRioPlatform },
+			// java artifacts are the same on every platform and have no debug
+			// variant
+			debug: true,
+			want: []string{
+				"com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.pom",
+				"com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar",
+			},
+		},
+		{
+			name: "jni",
+			dep: Vendordep{ JniDependencies: []JniDependency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", ValidPlatforms: []string{ "linuxathena", "linuxx86-64" } },
+			} },
+			// platforms the dependency isn't built for are skipped
+			platforms: []string{ "linuxathena", "windowsx86-64", "linuxx86-64" },
+			want: []string{
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3.pom",
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip",
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxx86-64.zip",
+			},
+		},
+		{
+			name: "jni debug jar",
+			dep: Vendordep{ JniDependencies: []JniDependency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", IsJar: true, ValidPlatforms: []string{ "linuxathena" } },
+			} },
+			platforms: []string{ "linuxathena" },
+			debug: true,
+			want: []string{
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3.pom",
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathenadebug.jar",
+			},
+		},
+		{
+			name: "c++ static",
+			dep: Vendordep{ CppDependencies: []CppDependency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-cpp", Version: "2025.0.3", HeaderClassifier: "headers", BinaryPlatforms: []string{ "linuxathena", "windowsx86-64" } },
+			} },
+			platforms: []string{ "linuxathena", "windowsx86-64" },
+			// sources are only for ides so they aren't fetched
+			want: []string{
+				"com/revrobotics/frc/REVLib-cpp/2025.0.3/REVLib-cpp-2025.0.3.pom",
+				"com/revrobotics/frc/REVLib-cpp/2025.0.3/REVLib-cpp-2025.0.3-headers.zip",
+				"com/revrobotics/frc/REVLib-cpp/2025.0.3/REVLib-cpp-2025.0.3-linuxathenastatic.zip",
+				"com/revrobotics/frc/REVLib-cpp/2025.0.3/REVLib-cpp-2025.0.3-windowsx86-64static.zip",
+			},
+		},
+		{
+			name: "c++ static debug",
+			dep: Vendordep{ CppDependencies: []CppDependency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-cpp", Version: "2025.0.3", HeaderClassifier: "headers", BinaryPlatforms: []string{ "linuxathena" } },
+			} },
+			platforms: []string{ "linuxathena" },
+			debug: true,
+			// headers have no debug variant
+			want: []string{
+				"com/revrobotics/frc/REVLib-cpp/2025.0.3/REVLib-cpp-2025.0.3.pom",
+				"com/revrobotics/frc/REVLib-cpp/2025.0.3/REVLib-cpp-2025.0.3-headers.zip",
+				"com/revrobotics/frc/REVLib-cpp/2025.0.3/REVLib-cpp-2025.0.3-linuxathenastaticdebug.zip",
+			},
+		},
+		{
+			name: "c++ shared without headers",
+			dep: Vendordep{ CppDependencies: []CppDependency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", SharedLibrary: true, BinaryPlatforms: []string{ "linuxathena" } },
+			} },
+			platforms: []string{ "linuxathena", "osxuniversal" },
+			debug: true,
+			want: []string{
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3.pom",
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathenadebug.zip",
+			},
+		},
+		{
+			name: "jni and c++ share an artifact",
+			dep: Vendordep{
+				JniDependencies: []JniDependency{
+					{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", ValidPlatforms: []string{ "linuxathena" } },
+				},
+				CppDependencies: []CppDependency{
+					{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", SharedLibrary: true, BinaryPlatforms: []string{ "linuxathena" } },
+				},
+			},
+			platforms: []string{ "linuxathena" },
+			want: []string{
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3.pom",
+				"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		artifacts := tt.dep.Artifacts(tt.platforms, tt.debug)
+		if got := artifactPaths(artifacts); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Artifacts =\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+
+		// only poms may be missing
+		for _, a := range artifacts {
+			if a.Optional != (a.Extension == "pom") {
+				t.Errorf("%s: %s optional is %v", tt.name, a, a.Optional)
+			}
+		}
+	}
+}
+
+func TestArtifactString(t *testing.T) {
+	a := Artifact{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-cpp", Version: "2025.0.3", Classifier: "headers", Extension: "zip" }
+	if a.String() != "com.revrobotics.frc:REVLib-cpp:2025.0.3:headers@zip" {
+		t.Errorf("String = %s", a)
+	}
+
+	a.Classifier = ""
+	if a.String() != "com.revrobotics.frc:REVLib-cpp:2025.0.3@zip" {
+		t.Errorf("String without a classifier = %s", a)
+	}
+}
+
+// testMaven serve the files in a map as a maven repository
+func testMaven(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[strings.TrimPrefix(r.URL.Path, "/maven/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/maven/"
+}
+
+func TestFetchArtifacts(t *testing.T) {
+	dep := Vendordep{
+		JavaDependencies: []JavaDepedency{
+			{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-java", Version: "2025.0.3" },
+		},
+		JniDependencies: []JniDependency{
+			{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", ValidPlatforms: []string{ "linuxathena" } },
+		},
+	}
+	artifacts := dep.Artifacts([]string{ RoboRioPlatform }, false)
+
+	// the first repository is missing the driver and every pom, the second has
+	// the driver
+	first := testMaven(t, map[string]string{
+		"com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar": "jar",
+	})
+	second := testMaven(t, map[string]string{
+		"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip": "zip",
+	})
+
+	repo := t.TempDir()
+	if missing := FetchArtifacts(repo, []string{ first, second }, artifacts, false); len(missing) != 0 {
+		t.Errorf("FetchArtifacts missed %v", missing)
+	}
+	for p, want := range map[string]string{
+		"com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar": "jar",
+		"com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip": "zip",
+	} {
+		data, err := os.ReadFile(filepath.Join(repo, filepath.FromSlash(p)))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v", p, data, err)
+		}
+	}
+
+	// downloaded artifacts aren't fetched again
+	if missing := FetchArtifacts(repo, nil, artifacts, false); len(missing) != 0 {
+		t.Errorf("FetchArtifacts fetched downloaded artifacts again: %v", missing)
+	}
+
+	// missing poms aren't a problem but the driver is
+	failedRepo := t.TempDir()
+	missing := FetchArtifacts(failedRepo, []string{ first }, artifacts, false)
+	if got := artifactPaths(missing); !slices.Equal(got, []string{ "com/revrobotics/frc/REVLib-driver/2025.0.3/REVLib-driver-2025.0.3-linuxathena.zip" }) {
+		t.Errorf("FetchArtifacts missing = %v", got)
+	}
+	entries, err := os.ReadDir(filepath.Join(failedRepo, "com", "revrobotics", "frc", "REVLib-driver", "2025.0.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".download-") {
+			t.Errorf("a failed download left %s behind", e.Name())
+		}
+	}
+}
+
+func TestMissingArtifactsError(t *testing.T) {
+	missing := []Artifact{ { GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-java", Version: "2025.0.3", Extension: "jar" } }
+
+	if err := MissingArtifactsError(nil, false); err != nil {
+		t.Errorf("nothing missing = %v", err)
+	}
+	if err := MissingArtifactsError(missing, false); err == nil || err.Error() != "unable to fetch 1 artifacts" {
+		t.Errorf("missing artifacts = %v", err)
+	}
+	if err := MissingArtifactsError(missing, true); err != nil {
+		t.Errorf("missing artifacts with allowMissing = %v", err)
+	}
+}
+
+func TestGradleInitScript(t *testing.T) {
+	want := "allprojects {\n  repositories {\n    maven { url = uri(\"file:///home/frc/.cache/rph/maven\") }\n  }\n}\n"
+	if got := GradleInitScript("/home/frc/.cache/rph/maven"); got != want {
+		t.Errorf("GradleInitScript =\n%s\nwant\n%s", got, want)
+	}
+
+	// windows paths need a leading slash and spaces escaping
+	if got := GradleRepoSnippet(`C:/Users/FRC Team/maven`); got != `maven { url = uri("file:///C:/Users/FRC%20Team/maven") }` {
+		t.Errorf("GradleRepoSnippet = %s", got)
+	}
+}
@@ -0,0 +1,140 @@
+package vendordep
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+const (
+	SeverityWarning = "warning"
+	SeverityError = "error"
+)
+
+// Problem something which will likely stop a project from building or running
+// on the robot
+type Problem struct {
+	Severity string
+	File string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.File + ": " + p.Message
+}
+
+// file the best name we've got for where a vendordep came from
+func (v *Vendordep) file() string {
+	if v.Path != "" {
+		return v.Path
+	}
+	return path.Join(vendordepDir, v.FileName)
+}
+
+// Check look for problems with a set of vendordeps being used together in a
+// project for year
+func Check(deps []Vendordep, year string) []Problem {
+	var problems []Problem
+
+	problem := func(severity string, dep Vendordep, format string, args ...any) {
+		problems = append(problems, Problem{
+			Severity: severity,
+			File: dep.file(),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	uuids := map[string]Vendordep{}
+	names := map[string]Vendordep{}
+
+	// the version of each groupId:artifactId and who asked for it
+	type artifactUse struct {
+		version string
+		dep Vendordep
+	}
+	artifacts := map[string]artifactUse{}
+
+	useArtifact := func(dep Vendordep, groupId, artifactId, version string) {
+		key := groupId + ":" + artifactId
+		use, ok := artifacts[key]
+		if !ok {
+			artifacts[key] = artifactUse{ version: version, dep: dep }
+			return
+		}
+
+		if use.version != version {
+			problem(SeverityError, dep, "%s %s conflicts with %s from %s", key, version, use.version, use.dep.file())
+		}
+	}
+
+	for _, dep := range deps {
+		if year != "" && dep.FrcYear != "" && string(dep.FrcYear) != year {
+			problem(SeverityError, dep, "%s is for %s but the project is for %s", dep.Name, dep.FrcYear, year)
+		}
+
+		if other, ok := uuids[dep.UUID]; ok && dep.UUID != "" {
+			problem(SeverityError, dep, "%s has the same uuid as %s", dep.Name, other.file())
+		} else {
+			uuids[dep.UUID] = dep
+		}
+
+		if other, ok := names[strings.ToLower(dep.Name)]; ok {
+			problem(SeverityError, dep, "%s has the same name as %s", dep.Name, other.file())
+		} else {
+			names[strings.ToLower(dep.Name)] = dep
+		}
+
+		for _, d := range dep.JavaDependencies {
+			useArtifact(dep, d.GroupId, d.ArtifactId, d.Version)
+		}
+
+		for _, d := range dep.JniDependencies {
+			useArtifact(dep, d.GroupId, d.ArtifactId, d.Version)
+			// software sim libraries only ever run on a computer, hardware
+			// sim ones are used on the robot too
+			if d.SimMode != SimModeSoftware && !slices.Contains(d.ValidPlatforms, RoboRioPlatform) {
+				problem(SeverityWarning, dep, "%s:%s does not support the roboRIO (%s)", d.GroupId, d.ArtifactId, RoboRioPlatform)
+			}
+		}
+
+		for _, d := range dep.CppDependencies {
+			useArtifact(dep, d.GroupId, d.ArtifactId, d.Version)
+			// software sim libraries only ever run on a computer, hardware
+			// sim ones are used on the robot too
+			if d.SimMode != SimModeSoftware && !slices.Contains(d.BinaryPlatforms, RoboRioPlatform) {
+				problem(SeverityWarning, dep, "%s:%s does not support the roboRIO (%s)", d.GroupId, d.ArtifactId, RoboRioPlatform)
+			}
+		}
+	}
+
+	for _, dep := range deps {
+		for _, r := range dep.MissingRequirements(deps) {
+			problem(SeverityWarning, dep, "%s requires %s which isn't installed: %s", dep.Name, requirementName(r.OfflineFileName, r.UUID), r.ErrorMessage)
+		}
+
+		for _, c := range dep.ConflictsWith {
+			if other, ok := uuids[c.UUID]; ok {
+				problem(SeverityError, dep, "%s conflicts with %s: %s", dep.Name, other.file(), c.ErrorMessage)
+			}
+		}
+	}
+
+	return problems
+}
+
+// requirementName the best name we've got for a vendordep we only know the uuid
+// of
+func requirementName(fileName string, uuid string) string {
+	if fileName != "" {
+		return fileName
+	}
+	return uuid
+}
+
+// HasErrors check if any of the problems are errors rather than warnings
+func HasErrors(problems []Problem) bool {
+	return slices.ContainsFunc(problems, func(p Problem) bool {
+		return p.Severity == SeverityError
+	})
+}
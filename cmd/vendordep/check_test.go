@@ -0,0 +1,52 @@
+package vendordep
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRoboRioSupport(t *testing.T) {
+	tests := []struct {
+		simMode string
+		platforms []string
+		warn bool
+	}{
+		{ "", []string{ "linuxx86-64" }, true },
+		{ "", []string{ RoboRioPlatform, "linuxx86-64" }, false },
+		// hardware sim libraries run on the robot as well
+		{ SimModeHardware, []string{ "linuxx86-64" }, true },
+		{ SimModeHardware, []string{ RoboRioPlatform }, false },
+		{ SimModeSoftware, []string{ "linuxx86-64" }, false },
+	}
+
+	for _, tt := range tests {
+		jni := Vendordep{
+			FileName: "Jni.json",
+			Name: "Jni",
+			JniDependencies: []JniDependency{
+				{ GroupId: "com.vendor", ArtifactId: "jni", Version: "1.0.0", ValidPlatforms: tt.platforms, SimMode: tt.simMode },
+			},
+		}
+		cpp := Vendordep{
+			FileName: "Cpp.json",
+			Name: "Cpp",
+			CppDependencies: []CppDependency{
+				{ GroupId: "com.vendor", ArtifactId: "cpp", Version: "1.0.0", BinaryPlatforms: tt.platforms, SimMode: tt.simMode },
+			},
+		}
+
+		for _, dep := range []Vendordep{ jni, cpp } {
+			problems := Check([]Vendordep{ dep }, "2025")
+
+			warned := false
+			for _, p := range problems {
+				if strings.Contains(p.Message, "does not support the roboRIO") {
+					warned = true
+				}
+			}
+			if warned != tt.warn {
+				t.Errorf("%s with simMode %q and platforms %v: warned = %v, want %v (%v)", dep.Name, tt.simMode, tt.platforms, warned, tt.warn, problems)
+			}
+		}
+	}
+}
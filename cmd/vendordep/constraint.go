@@ -0,0 +1,139 @@
+package vendordep
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// Constraint limits which versions of a vendordep may be installed, e.g.
+// "^2025.3" allows any 2025 release from 2025.3 onwards and "~25.1" allows any
+// 25.1.x release.
+type Constraint struct {
+	// Op one of "^", "~", "=", ">", ">=", "<" or "<=", empty means any version
+	Op string
+	Version Version
+}
+
+// the order matters, longer operators have to be checked first
+var constraintOps = []string{ ">=", "<=", "^", "~", "=", ">", "<" }
+
+// ParseConstraint parse a version constraint, "latest", "*" and "" allow any
+// version and a bare version only allows that exact version.
+func ParseConstraint(s string) (Constraint, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" || strings.EqualFold(s, "latest") {
+		return Constraint{}, nil
+	}
+
+	op := "="
+	for _, o := range constraintOps {
+		if rest, ok := strings.CutPrefix(s, o); ok {
+			op = o
+			s = strings.TrimSpace(rest)
+			break
+		}
+	}
+
+	v, err := ParseVersion(s)
+	if err != nil {
+		return Constraint{}, errors.New("invalid version constraint: " + op + s)
+	}
+
+	return Constraint{ Op: op, Version: v }, nil
+}
+
+// SplitConstraint split "photonlib@^2025.3" into the vendordep and the
+// constraint, ok is false when there's no constraint. Urls are never split.
+func SplitConstraint(arg string) (name string, constraint string, ok bool) {
+	if strings.HasPrefix(arg, "http") {
+		return arg, "", false
+	}
+	return strings.Cut(arg, "@")
+}
+
+func (c Constraint) String() string {
+	if c.Any() {
+		return "latest"
+	}
+	return c.Op + c.Version.String()
+}
+
+// Any check if the constraint allows every version
+func (c Constraint) Any() bool {
+	return c.Op == ""
+}
+
+// Allows check if version satisfies the constraint. Pre-releases are only
+// allowed when the constraint itself names a pre-release, or when it allows
+// any version.
+func (c Constraint) Allows(version string) bool {
+	if c.Any() {
+		return true
+	}
+
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	if v.IsPreRelease() && !c.Version.IsPreRelease() {
+		return false
+	}
+
+	cmp := v.Compare(c.Version)
+	switch c.Op {
+	case "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "^":
+		// the first part is the major version, unless it's zero in which case
+		// the second part is treated as the major version like semver does
+		fixed := 1
+		if c.Version.Parts[0] == 0 && len(c.Version.Parts) > 1 {
+			fixed = 2
+		}
+		return cmp >= 0 && samePrefix(v, c.Version, fixed)
+	case "~":
+		return cmp >= 0 && samePrefix(v, c.Version, min(2, len(c.Version.Parts)))
+	}
+
+	return false
+}
+
+// samePrefix check if the first n parts of two versions are the same
+func samePrefix(a, b Version, n int) bool {
+	for i := range n {
+		var x, y int
+		if i < len(a.Parts) { x = a.Parts[i] }
+		if i < len(b.Parts) { y = b.Parts[i] }
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
+
+// Constraints get the constraint of every locked vendordep which has one keyed
+// by the vendordep's file name, constraints which can't be parsed are ignored.
+func (l *Lock) Constraints() map[string]Constraint {
+	constraints := make(map[string]Constraint)
+	for _, e := range l.Vendordeps {
+		if e.Constraint == "" { continue }
+
+		c, err := ParseConstraint(e.Constraint)
+		if err != nil {
+			slog.Warn("Ignoring invalid version constraint in lockfile", "name", e.Name, "constraint", e.Constraint)
+			continue
+		}
+		constraints[e.FileName] = c
+	}
+
+	return constraints
+}
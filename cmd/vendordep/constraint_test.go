@@ -0,0 +1,114 @@
+package vendordep
+
+import "testing"
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		in string
+		op string
+		version string
+	}{
+		{ "", "", "" },
+		{ "*", "", "" },
+		{ "latest", "", "" },
+		{ "LATEST", "", "" },
+		{ "2025.3.1", "=", "2025.3.1" },
+		{ "=2025.3.1", "=", "2025.3.1" },
+		{ "^2025.3", "^", "2025.3" },
+		{ "~25.1", "~", "25.1" },
+		{ ">=2025.1.0", ">=", "2025.1.0" },
+		{ "> 2025.1.0", ">", "2025.1.0" },
+		{ "<=2025.1.0", "<=", "2025.1.0" },
+		{ "<2025.1.0", "<", "2025.1.0" },
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.in)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if c.Op != tt.op || (tt.op != "" && c.Version.String() != tt.version) {
+			t.Errorf("ParseConstraint(%q) = %q %q, want %q %q", tt.in, c.Op, c.Version, tt.op, tt.version)
+		}
+	}
+
+	for _, in := range []string{ "^", ">=latest", "~abc" } {
+		if _, err := ParseConstraint(in); err == nil {
+			t.Errorf("ParseConstraint(%q) should have failed", in)
+		}
+	}
+}
+
+func TestConstraintAllows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		allowed []string
+		denied []string
+	}{
+		{ "latest", []string{ "2025.3.1", "2026.0.0-beta-1", "not a version" }, nil },
+		{ "2025.3.1", []string{ "2025.3.1", "v2025.3.1" }, []string{ "2025.3.2", "2025.3.0" } },
+		{ "^2025.3", []string{ "2025.3.0", "2025.3.1", "2025.10.0" }, []string{ "2025.2.9", "2026.0.0", "2025.4.0-beta-1" } },
+		{ "^0.3.1", []string{ "0.3.1", "0.3.9" }, []string{ "0.4.0", "0.3.0", "1.0.0" } },
+		{ "~25.1", []string{ "25.1.0", "25.1.7" }, []string{ "25.2.0", "25.0.9", "26.1.0" } },
+		{ "~25.1.2", []string{ "25.1.2", "25.1.3" }, []string{ "25.1.1", "25.2.0" } },
+		{ ">=2025.1.0", []string{ "2025.1.0", "2026.0.0" }, []string{ "2024.9.9", "not a version" } },
+		{ ">2025.1.0", []string{ "2025.1.1" }, []string{ "2025.1.0" } },
+		{ "<2025.1.0", []string{ "2025.0.9" }, []string{ "2025.1.0" } },
+		{ "<=2025.1.0", []string{ "2025.1.0", "2025.0.9" }, []string{ "2025.1.1" } },
+		// pre-releases are only allowed when the constraint asks for one
+		{ ">=2025.0.0-beta-1", []string{ "2025.0.0-beta-2", "2025.0.0" }, []string{ "2025.0.0-alpha-1" } },
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+		}
+
+		for _, v := range tt.allowed {
+			if !c.Allows(v) {
+				t.Errorf("%s should allow %s", tt.constraint, v)
+			}
+		}
+		for _, v := range tt.denied {
+			if c.Allows(v) {
+				t.Errorf("%s shouldn't allow %s", tt.constraint, v)
+			}
+		}
+	}
+}
+
+func TestSplitConstraint(t *testing.T) {
+	tests := []struct {
+		in, name, constraint string
+		ok bool
+	}{
+		{ "photonlib@^2025.3", "photonlib", "^2025.3", true },
+		{ "photonlib", "photonlib", "", false },
+		{ "https://example.com/lib@2025.json", "https://example.com/lib@2025.json", "", false },
+	}
+
+	for _, tt := range tests {
+		name, constraint, ok := SplitConstraint(tt.in)
+		if name != tt.name || constraint != tt.constraint || ok != tt.ok {
+			t.Errorf("SplitConstraint(%q) = %q, %q, %v, want %q, %q, %v", tt.in, name, constraint, ok, tt.name, tt.constraint, tt.ok)
+		}
+	}
+}
+
+func TestLockConstraints(t *testing.T) {
+	lock := Lock{ Vendordeps: []LockEntry{
+		{ Name: "REVLib", FileName: "REVLib.json", Constraint: "^2025.0" },
+		{ Name: "photonlib", FileName: "photonlib.json" },
+		{ Name: "broken", FileName: "broken.json", Constraint: "^nope" },
+	} }
+
+	constraints := lock.Constraints()
+	if len(constraints) != 1 {
+		t.Fatalf("expected only REVLib to have a constraint, got %v", constraints)
+	}
+	if c := constraints["REVLib.json"]; c.String() != "^2025.0" {
+		t.Errorf("REVLib constraint = %s, want ^2025.0", c)
+	}
+}
@@ -0,0 +1,187 @@
+package vendordep
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	ChangeAdded = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change a single difference between two versions of a vendordep
+type Change struct {
+	Kind string `json:"kind"`
+	// Section which part of the vendordep changed, e.g. "mavenUrls" or
+	// "jniDependencies", empty for the top level fields
+	Section string `json:"section,omitempty"`
+	// Artifact the groupId:artifactId of the dependency which changed
+	Artifact string `json:"artifact,omitempty"`
+	Field string `json:"field,omitempty"`
+	From string `json:"from,omitempty"`
+	To string `json:"to,omitempty"`
+}
+
+func (c Change) String() string {
+	where := c.Section
+	if c.Artifact != "" {
+		where += " " + c.Artifact
+	}
+	if c.Field != "" {
+		if where != "" {
+			where += " "
+		}
+		where += c.Field
+	}
+
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", where, c.To)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", where, c.From)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", where, c.From, c.To)
+}
+
+// Diff find the structural differences between two versions of a vendordep.
+// Dependencies are matched up by their groupId and artifactId so a dependency
+// moving around in the file isn't counted as a change.
+func Diff(from *Vendordep, to *Vendordep) []Change {
+	var changes []Change
+
+	field := func(name string, a, b string) {
+		if a != b {
+			changes = append(changes, Change{ Kind: ChangeChanged, Field: name, From: a, To: b })
+		}
+	}
+	field("name", from.Name, to.Name)
+	field("version", from.Version, to.Version)
+	field("fileName", from.FileName, to.FileName)
+	field("frcYear", string(from.FrcYear), string(to.FrcYear))
+	field("uuid", from.UUID, to.UUID)
+	field("jsonUrl", from.JsonUrl, to.JsonUrl)
+
+	changes = append(changes, diffList("mavenUrls", "", "", from.MavenUrls, to.MavenUrls)...)
+
+	changes = append(changes, diffDeps("javaDependencies", from.JavaDependencies, to.JavaDependencies,
+		func(d JavaDepedency) string { return d.GroupId + ":" + d.ArtifactId },
+		func(d JavaDepedency) string { return d.Version },
+		func(a, b JavaDepedency) []Change {
+			return diffFields(map[string][2]string{
+				"version": { a.Version, b.Version },
+			})
+		})...)
+
+	changes = append(changes, diffDeps("jniDependencies", from.JniDependencies, to.JniDependencies,
+		func(d JniDependency) string { return d.GroupId + ":" + d.ArtifactId },
+		func(d JniDependency) string { return d.Version },
+		func(a, b JniDependency) []Change {
+			changes := diffFields(map[string][2]string{
+				"version": { a.Version, b.Version },
+				"isJar": { strconv.FormatBool(a.IsJar), strconv.FormatBool(b.IsJar) },
+				"skipInvalidPlatforms": { strconv.FormatBool(a.SkipInvalidPlatforms), strconv.FormatBool(b.SkipInvalidPlatforms) },
+				"simMode": { a.SimMode, b.SimMode },
+			})
+			return append(changes, diffList("", "", "validPlatforms", a.ValidPlatforms, b.ValidPlatforms)...)
+		})...)
+
+	changes = append(changes, diffDeps("cppDependencies", from.CppDependencies, to.CppDependencies,
+		func(d CppDependency) string { return d.GroupId + ":" + d.ArtifactId },
+		func(d CppDependency) string { return d.Version },
+		func(a, b CppDependency) []Change {
+			changes := diffFields(map[string][2]string{
+				"version": { a.Version, b.Version },
+				"libName": { a.LibName, b.LibName },
+				"headerClassifier": { a.HeaderClassifier, b.HeaderClassifier },
+				"sharedLibrary": { strconv.FormatBool(a.SharedLibrary), strconv.FormatBool(b.SharedLibrary) },
+				"skipInvalidPlatforms": { strconv.FormatBool(a.SkipInvalidPlatforms), strconv.FormatBool(b.SkipInvalidPlatforms) },
+				"simMode": { a.SimMode, b.SimMode },
+			})
+			return append(changes, diffList("", "", "binaryPlatforms", a.BinaryPlatforms, b.BinaryPlatforms)...)
+		})...)
+
+	return changes
+}
+
+// diffFields compare named pairs of values, the changes are sorted by field
+// name so the output is stable
+func diffFields(fields map[string][2]string) []Change {
+	var changes []Change
+	for name, v := range fields {
+		if v[0] != v[1] {
+			changes = append(changes, Change{ Kind: ChangeChanged, Field: name, From: v[0], To: v[1] })
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b Change) int {
+		// always show the version first, it's what people care about the most
+		if a.Field == "version" { return -1 }
+		if b.Field == "version" { return 1 }
+		return strings.Compare(a.Field, b.Field)
+	})
+	return changes
+}
+
+// diffList compare two lists as sets, reporting every added and removed value
+func diffList(section string, artifact string, field string, from []string, to []string) []Change {
+	var changes []Change
+	for _, v := range from {
+		if !slices.Contains(to, v) {
+			changes = append(changes, Change{ Kind: ChangeRemoved, Section: section, Artifact: artifact, Field: field, From: v })
+		}
+	}
+	for _, v := range to {
+		if !slices.Contains(from, v) {
+			changes = append(changes, Change{ Kind: ChangeAdded, Section: section, Artifact: artifact, Field: field, To: v })
+		}
+	}
+
+	return changes
+}
+
+// diffDeps compare two lists of dependencies, dependencies are matched up using
+// key and then compared with fields
+func diffDeps[T any](section string, from []T, to []T, key func(T) string, version func(T) string, fields func(a, b T) []Change) []Change {
+	var changes []Change
+
+	for _, a := range from {
+		i := slices.IndexFunc(to, func(b T) bool { return key(a) == key(b) })
+		if i < 0 {
+			changes = append(changes, Change{ Kind: ChangeRemoved, Section: section, Artifact: key(a), From: version(a) })
+			continue
+		}
+
+		for _, c := range fields(a, to[i]) {
+			c.Section = section
+			c.Artifact = key(a)
+			changes = append(changes, c)
+		}
+	}
+
+	for _, b := range to {
+		if !slices.ContainsFunc(from, func(a T) bool { return key(a) == key(b) }) {
+			changes = append(changes, Change{ Kind: ChangeAdded, Section: section, Artifact: key(b), To: version(b) })
+		}
+	}
+
+	return changes
+}
+
+// ShowDiff write out the changes between two versions of a vendordep in a
+// human readable format
+func ShowDiff(w io.Writer, from *Vendordep, to *Vendordep, changes []Change) {
+	fmt.Fprintf(w, "%s %s -> %s %s\n", from.Name, from.Version, to.Name, to.Version)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes")
+		return
+	}
+
+	for _, c := range changes {
+		fmt.Fprintln(w, c)
+	}
+}
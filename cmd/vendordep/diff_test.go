@@ -0,0 +1,124 @@
+package vendordep
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func diffStrings(changes []Change) []string {
+	var s []string
+	for _, c := range changes {
+		s = append(s, c.String())
+	}
+	return s
+}
+
+func diffTestVendordep() *Vendordep {
+	return &Vendordep{
+		FileName: "REVLib.json",
+		Name: "REVLib",
+		Version: "2025.0.2",
+		FrcYear: "2025",
+		UUID: testUUID,
+		MavenUrls: []string{ "https://maven.revrobotics.com/" },
+		JavaDependencies: []JavaDepedency{
+			{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-java", Version: "2025.0.2" },
+			{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-util", Version: "2025.0.2" },
+		},
+		JniDependencies: []JniDependency{
+			{
+				GroupId: "com.revrobotics.frc",
+				ArtifactId: "REVLib-driver",
+				Version: "2025.0.2",
+				ValidPlatforms: []string{ "linuxathena", "linuxx86-64" },
+			},
+		},
+		CppDependencies: []CppDependency{
+			{
+				GroupId: "com.revrobotics.frc",
+				ArtifactId: "REVLib-cpp",
+				Version: "2025.0.2",
+				LibName: "REVLib",
+				HeaderClassifier: "headers",
+				BinaryPlatforms: []string{ "linuxathena", "windowsx86-64" },
+			},
+		},
+	}
+}
+
+func TestDiffSame(t *testing.T) {
+	if changes := Diff(diffTestVendordep(), diffTestVendordep()); len(changes) != 0 {
+		t.Errorf("Diff of the same vendordep = %v", diffStrings(changes))
+	}
+}
+
+func TestDiffMatchesArtifacts(t *testing.T) {
+	from := diffTestVendordep()
+	to := diffTestVendordep()
+
+	// moving dependencies around isn't a change
+	slices.Reverse(to.JavaDependencies)
+
+	// artifacts are matched by groupId:artifactId, a new artifactId is a
+	// different artifact
+	to.JavaDependencies[0].ArtifactId = "REVLib-utils"
+	to.JavaDependencies = append(to.JavaDependencies, JavaDepedency{ GroupId: "com.revrobotics.other", ArtifactId: "REVLib-java", Version: "1.0.0" })
+
+	want := []string{
+		"- javaDependencies com.revrobotics.frc:REVLib-util: 2025.0.2",
+		"+ javaDependencies com.revrobotics.frc:REVLib-utils: 2025.0.2",
+		"+ javaDependencies com.revrobotics.other:REVLib-java: 1.0.0",
+	}
+	if got := diffStrings(Diff(from, to)); !slices.Equal(got, want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+}
+
+func TestDiffChanges(t *testing.T) {
+	from := diffTestVendordep()
+	to := diffTestVendordep()
+
+	to.Version = "2025.0.3"
+	to.MavenUrls = []string{ "https://maven.revrobotics.com/", "https://frcmaven.wpi.edu/artifactory/release/" }
+	to.JavaDependencies[0].Version = "2025.0.3"
+
+	jni := &to.JniDependencies[0]
+	jni.Version = "2025.0.3"
+	jni.SimMode = SimModeHardware
+	jni.IsJar = true
+	jni.ValidPlatforms = []string{ "linuxathena", "osxuniversal" }
+
+	cpp := &to.CppDependencies[0]
+	cpp.SimMode = SimModeSoftware
+	cpp.BinaryPlatforms = []string{ "windowsx86-64", "linuxathena" }
+
+	want := []string{
+		"~ version: 2025.0.2 -> 2025.0.3",
+		"+ mavenUrls: https://frcmaven.wpi.edu/artifactory/release/",
+		"~ javaDependencies com.revrobotics.frc:REVLib-java version: 2025.0.2 -> 2025.0.3",
+		// the version always comes first
+		"~ jniDependencies com.revrobotics.frc:REVLib-driver version: 2025.0.2 -> 2025.0.3",
+		"~ jniDependencies com.revrobotics.frc:REVLib-driver isJar: false -> true",
+		"~ jniDependencies com.revrobotics.frc:REVLib-driver simMode:  -> hwsim",
+		"- jniDependencies com.revrobotics.frc:REVLib-driver validPlatforms: linuxx86-64",
+		"+ jniDependencies com.revrobotics.frc:REVLib-driver validPlatforms: osxuniversal",
+		// platforms are compared as sets
+		"~ cppDependencies com.revrobotics.frc:REVLib-cpp simMode:  -> swsim",
+	}
+	if got := diffStrings(Diff(from, to)); !slices.Equal(got, want) {
+		t.Errorf("Diff =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestShowDiff(t *testing.T) {
+	from := diffTestVendordep()
+	to := diffTestVendordep()
+
+	var buf bytes.Buffer
+	ShowDiff(&buf, from, to, Diff(from, to))
+	if buf.String() != "REVLib 2025.0.2 -> REVLib 2025.0.2\nNo changes\n" {
+		t.Errorf("ShowDiff = %q", buf.String())
+	}
+}
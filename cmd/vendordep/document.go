@@ -0,0 +1,403 @@
+package vendordep
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MetadataKey the key rph keeps its own information under inside of a
+// vendordep file, gradle ignores keys it doesn't know about
+const MetadataKey = "rphMetadata"
+
+// node where a json value is in a document. Objects keep their keys in the
+// order they appear in the file.
+type node struct {
+	start, end int
+	// from the start of each key to the start of its value, only set for
+	// objects
+	keys []string
+	keySpans [][2]int
+	children []*node
+	object bool
+	array bool
+}
+
+// child find the value of key in an object, or the element at index key in an
+// array
+func (n *node) child(key string) (*node, int) {
+	if n.object {
+		for i, k := range n.keys {
+			if k == key {
+				return n.children[i], i
+			}
+		}
+	} else if n.array {
+		i, err := strconv.Atoi(key)
+		if err == nil && i >= 0 && i < len(n.children) {
+			return n.children[i], i
+		}
+	}
+
+	return nil, -1
+}
+
+type docParser struct {
+	data []byte
+	dec *json.Decoder
+}
+
+// skip find where the next value or key starts, json only allows whitespace,
+// colons and commas between tokens
+func (p *docParser) skip() int {
+	i := int(p.dec.InputOffset())
+	for i < len(p.data) && strings.ContainsRune(" \t\r\n:,", rune(p.data[i])) {
+		i++
+	}
+	return i
+}
+
+func (p *docParser) value() (*node, error) {
+	start := p.skip()
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &node{ start: start }
+	switch tok {
+	case json.Delim('{'):
+		n.object = true
+		for p.dec.More() {
+			keyStart := p.skip()
+			key, err := p.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			child, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+
+			n.keys = append(n.keys, key.(string))
+			n.keySpans = append(n.keySpans, [2]int{ keyStart, child.start })
+			n.children = append(n.children, child)
+		}
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	case json.Delim('['):
+		n.array = true
+		for p.dec.More() {
+			child, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		}
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	n.end = int(p.dec.InputOffset())
+	return n, nil
+}
+
+// Document a vendordep file which can be edited without losing anything rph
+// doesn't understand. Keys stay in the order they were written and everything
+// that isn't edited keeps its exact formatting.
+type Document struct {
+	data []byte
+	root *node
+}
+
+func ParseDocument(data []byte) (*Document, error) {
+	d := &Document{ data: bytes.Clone(data) }
+	if err := d.parse(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *Document) parse() error {
+	p := &docParser{ data: d.data, dec: json.NewDecoder(bytes.NewReader(d.data)) }
+	p.dec.UseNumber()
+
+	root, err := p.value()
+	if err != nil {
+		return err
+	}
+	if !root.object {
+		return errors.New("vendordep must be a json object")
+	}
+
+	d.root = root
+	return nil
+}
+
+// Bytes the document as it should be written to disk
+func (d *Document) Bytes() []byte {
+	return d.data
+}
+
+// Vendordep parse the document as a vendordep
+func (d *Document) Vendordep() (*Vendordep, error) {
+	return Parse(bytes.NewReader(d.data))
+}
+
+// find the node at path, along with its parent
+func (d *Document) find(path []string) (*node, *node) {
+	parent, n := (*node)(nil), d.root
+	for _, key := range path {
+		if n == nil {
+			return nil, nil
+		}
+		parent = n
+		n, _ = n.child(key)
+	}
+
+	return n, parent
+}
+
+// Get the raw json at path, e.g. Get("javaDependencies", "0", "version")
+func (d *Document) Get(path ...string) (json.RawMessage, bool) {
+	n, _ := d.find(path)
+	if n == nil {
+		return nil, false
+	}
+
+	return json.RawMessage(d.data[n.start:n.end]), true
+}
+
+// indentOf the whitespace at the start of the line pos is on
+func (d *Document) indentOf(pos int) string {
+	lineStart := bytes.LastIndexByte(d.data[:pos], '\n') + 1
+	end := lineStart
+	for end < pos && (d.data[end] == ' ' || d.data[end] == '\t') {
+		end++
+	}
+	return string(d.data[lineStart:end])
+}
+
+// encode marshal a value to sit at pos, values are only spread across lines
+// when the value they replace was
+func (d *Document) encode(value any, pos int, multiline bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if multiline {
+		enc.SetIndent(d.indentOf(pos), d.indentStep())
+	}
+
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\n"), []byte(d.newline())), nil
+}
+
+// newline the line ending the file uses
+func (d *Document) newline() string {
+	if bytes.Contains(d.data, []byte("\r\n")) {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// indentStep guess how far the file indents each level, by looking at the
+// first key in the file
+func (d *Document) indentStep() string {
+	if len(d.root.keySpans) > 0 {
+		if indent := d.indentOf(d.root.keySpans[0][0]); indent != "" {
+			return indent
+		}
+	}
+	return "  "
+}
+
+func (d *Document) replace(start, end int, with []byte) error {
+	data := make([]byte, 0, len(d.data) - (end - start) + len(with))
+	data = append(data, d.data[:start]...)
+	data = append(data, with...)
+	data = append(data, d.data[end:]...)
+
+	old := d.data
+	d.data = data
+	if err := d.parse(); err != nil {
+		d.data = old
+		d.parse()
+		return err
+	}
+	return nil
+}
+
+// Set change the value at path, keys which don't exist yet are added to the
+// end of their object. Arrays are never grown, only existing elements may be
+// set.
+func (d *Document) Set(value any, path ...string) error {
+	if len(path) == 0 {
+		return errors.New("no path to set")
+	}
+
+	// find the deepest part of the path which exists already, everything after
+	// it gets created as nested objects
+	n := d.root
+	depth := 0
+	for depth < len(path) {
+		child, _ := n.child(path[depth])
+		if child == nil { break }
+		n = child
+		depth++
+	}
+
+	if depth == len(path) {
+		multiline := bytes.ContainsRune(d.data[n.start:n.end], '\n')
+		data, err := d.encode(value, n.start, multiline)
+		if err != nil {
+			return err
+		}
+		return d.replace(n.start, n.end, data)
+	}
+
+	if !n.object {
+		return fmt.Errorf("%s is not an object", strings.Join(path[:depth], "."))
+	}
+	for i := len(path) - 1; i > depth; i-- {
+		value = map[string]any{ path[i]: value }
+	}
+	return d.insert(n, path[depth], value)
+}
+
+// insert add a key to the end of an object, following the formatting of the
+// keys already in it
+func (d *Document) insert(obj *node, key string, value any) error {
+	keyData, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	if len(obj.keys) == 0 {
+		// there's nothing to copy the formatting of
+		indent := d.indentOf(obj.start)
+		data, err := d.encode(value, obj.start, true)
+		if err != nil {
+			return err
+		}
+		nl := d.newline()
+		data = bytes.ReplaceAll(data, []byte(nl), []byte(nl + d.indentStep()))
+
+		insert := nl + indent + d.indentStep() + string(keyData) + ": " + string(data) + nl + indent
+		return d.replace(obj.start + 1, obj.end - 1, []byte(insert))
+	}
+
+	last := len(obj.keys) - 1
+	lastKey := obj.keySpans[last]
+	lastValue := obj.children[last]
+
+	// whatever separated the last key from the one before it, falling back on
+	// a space for single line objects
+	sep := " "
+	if bytes.ContainsRune(d.data[obj.start:obj.end], '\n') {
+		sep = d.newline() + d.indentOf(lastKey[0])
+	}
+
+	// the colon and whitespace between the last key and its value
+	span := d.data[lastKey[0]:lastKey[1]]
+	colon := string(span[bytes.LastIndexByte(span, ':'):])
+
+	data, err := d.encode(value, lastKey[0], sep != " ")
+	if err != nil {
+		return err
+	}
+
+	insert := "," + sep + string(keyData) + colon + string(data)
+	return d.replace(lastValue.end, lastValue.end, []byte(insert))
+}
+
+// Remove delete the value at path along with its key or comma
+func (d *Document) Remove(path ...string) error {
+	n, parent := d.find(path)
+	if n == nil || parent == nil {
+		return fmt.Errorf("%s not found", strings.Join(path, "."))
+	}
+
+	_, i := parent.child(path[len(path) - 1])
+
+	start := n.start
+	if parent.object {
+		start = parent.keySpans[i][0]
+	}
+
+	// take the comma and whitespace before us, or after us when we're first
+	if i > 0 {
+		return d.replace(parent.children[i - 1].end, n.end, nil)
+	}
+	if len(parent.children) > 1 {
+		next := parent.children[1].start
+		if parent.object {
+			next = parent.keySpans[1][0]
+		}
+		return d.replace(start, next, nil)
+	}
+	return d.replace(start, n.end, nil)
+}
+
+// Pin set the version of the vendordep and of the dependencies which were
+// released alongside it, this is useful for holding a vendordep back when the
+// vendor publishes all of their artifacts under the same version. Dependencies
+// with their own version, like a bundled third party library, are left alone.
+func (d *Document) Pin(version string) error {
+	var old string
+	if raw, ok := d.Get("version"); ok {
+		json.Unmarshal(raw, &old)
+	}
+
+	if err := d.Set(version, "version"); err != nil {
+		return err
+	}
+
+	for _, list := range []string{ "javaDependencies", "jniDependencies", "cppDependencies" } {
+		deps, _ := d.find([]string{ list })
+		if deps == nil || !deps.array { continue }
+
+		for i := range deps.children {
+			var current string
+			if raw, ok := d.Get(list, strconv.Itoa(i), "version"); ok {
+				json.Unmarshal(raw, &current)
+			}
+			if old == "" || current != old { continue }
+
+			if err := d.Set(version, list, strconv.Itoa(i), "version"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetMavenUrls replace the maven repositories the vendordep's artifacts are
+// downloaded from, e.g. to point them at a mirror
+func (d *Document) SetMavenUrls(urls []string) error {
+	return d.Set(urls, "mavenUrls")
+}
+
+// Tag record a piece of metadata in the vendordep file under MetadataKey
+func (d *Document) Tag(key string, value string) error {
+	return d.Set(value, MetadataKey, key)
+}
+
+// Metadata everything which has been tagged onto the vendordep
+func (d *Document) Metadata() map[string]string {
+	metadata := map[string]string{}
+	if raw, ok := d.Get(MetadataKey); ok {
+		json.Unmarshal(raw, &metadata)
+	}
+	return metadata
+}
@@ -0,0 +1,213 @@
+package vendordep
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDocument = `{
+    "fileName": "Lib.json",
+    "name": "Lib",
+    "version": "2025.1.0",
+    "vendorThing": { "keep": [1, 2.50, "me"] },
+    "mavenUrls": [
+        "https://maven.example.com"
+    ],
+    "javaDependencies": [
+        {
+            "groupId": "com.example",
+            "artifactId": "lib-java",
+            "version": "2025.1.0"
+        },
+        {
+            "groupId": "org.thirdparty",
+            "artifactId": "json",
+            "version": "3.1.4"
+        }
+    ],
+    "jniDependencies": [],
+    "cppDependencies": [
+        { "groupId": "com.example", "artifactId": "lib-cpp", "version": "2025.1.0" }
+    ]
+}
+`
+
+func testDoc(t *testing.T, data string) *Document {
+	t.Helper()
+
+	d, err := ParseDocument([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func expectDoc(t *testing.T, d *Document, want string) {
+	t.Helper()
+
+	if got := string(d.Bytes()); got != want {
+		t.Errorf("document is:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDocumentSetKeepsFormatting(t *testing.T) {
+	d := testDoc(t, testDocument)
+
+	if err := d.Set("2025.2.0", "javaDependencies", "1", "version"); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the value changes, the odd spacing and numbers of the key rph
+	// doesn't know about are kept exactly
+	expectDoc(t, d, strings.Replace(testDocument, `"3.1.4"`, `"2025.2.0"`, 1))
+}
+
+func TestDocumentGet(t *testing.T) {
+	d := testDoc(t, testDocument)
+
+	raw, ok := d.Get("cppDependencies", "0", "artifactId")
+	if !ok || string(raw) != `"lib-cpp"` {
+		t.Errorf("Get = %s, %v", raw, ok)
+	}
+	if _, ok := d.Get("javaDependencies", "2"); ok {
+		t.Error("Get found an element past the end of an array")
+	}
+	if _, ok := d.Get("nope"); ok {
+		t.Error("Get found a key which doesn't exist")
+	}
+}
+
+func TestDocumentSetMultiline(t *testing.T) {
+	d := testDoc(t, testDocument)
+
+	if err := d.SetMavenUrls([]string{ "http://mirror.local/maven", "https://maven.example.com" }); err != nil {
+		t.Fatal(err)
+	}
+
+	expectDoc(t, d, strings.Replace(testDocument, `    "mavenUrls": [
+        "https://maven.example.com"
+    ],`, `    "mavenUrls": [
+        "http://mirror.local/maven",
+        "https://maven.example.com"
+    ],`, 1))
+}
+
+func TestDocumentTag(t *testing.T) {
+	d := testDoc(t, `{
+  "name": "Lib",
+  "version": "1.0.0"
+}`)
+
+	if err := d.Tag("reason", "waiting on a fix"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Tag("by", "<pit crew>"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectDoc(t, d, `{
+  "name": "Lib",
+  "version": "1.0.0",
+  "rphMetadata": {
+    "reason": "waiting on a fix",
+    "by": "<pit crew>"
+  }
+}`)
+
+	metadata := d.Metadata()
+	if len(metadata) != 2 || metadata["reason"] != "waiting on a fix" || metadata["by"] != "<pit crew>" {
+		t.Errorf("Metadata = %v", metadata)
+	}
+}
+
+func TestDocumentInsertSingleLine(t *testing.T) {
+	d := testDoc(t, `{"name": "Lib", "extra": {}}`)
+
+	if err := d.Set("1.0.0", "version"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set(true, "extra", "flag"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectDoc(t, d, `{"name": "Lib", "extra": {
+  "flag": true
+}, "version": "1.0.0"}`)
+}
+
+func TestDocumentKeepsCRLF(t *testing.T) {
+	d := testDoc(t, "{\r\n  \"name\": \"Lib\"\r\n}\r\n")
+
+	if err := d.Tag("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectDoc(t, d, "{\r\n  \"name\": \"Lib\",\r\n  \"rphMetadata\": {\r\n    \"k\": \"v\"\r\n  }\r\n}\r\n")
+}
+
+func TestDocumentRemove(t *testing.T) {
+	tests := []struct {
+		path []string
+		want string
+	}{
+		{ []string{ "a" }, `{ "b": 2, "c": [1, 2] }` },
+		{ []string{ "b" }, `{ "a": 1, "c": [1, 2] }` },
+		{ []string{ "c" }, `{ "a": 1, "b": 2 }` },
+		{ []string{ "c", "0" }, `{ "a": 1, "b": 2, "c": [2] }` },
+	}
+
+	for _, tt := range tests {
+		d := testDoc(t, `{ "a": 1, "b": 2, "c": [1, 2] }`)
+		if err := d.Remove(tt.path...); err != nil {
+			t.Errorf("Remove(%v) failed: %v", tt.path, err)
+			continue
+		}
+		expectDoc(t, d, tt.want)
+	}
+
+	d := testDoc(t, `{ "a": 1 }`)
+	if err := d.Remove("b"); err == nil {
+		t.Error("Remove of a missing key should fail")
+	}
+}
+
+func TestDocumentPin(t *testing.T) {
+	d := testDoc(t, testDocument)
+
+	if err := d.Pin("2025.0.3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the third party library has its own version so it's left alone
+	want := strings.ReplaceAll(testDocument, `"2025.1.0"`, `"2025.0.3"`)
+	expectDoc(t, d, want)
+	if !strings.Contains(want, `"3.1.4"`) {
+		t.Fatal("the test document lost its third party library")
+	}
+
+	dep, err := d.Vendordep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep.Version != "2025.0.3" || dep.JavaDependencies[1].Version != "3.1.4" {
+		t.Errorf("pinned vendordep is %s with json %s", dep.Version, dep.JavaDependencies[1].Version)
+	}
+}
+
+func TestDocumentErrors(t *testing.T) {
+	if _, err := ParseDocument([]byte(`[1, 2]`)); err == nil {
+		t.Error("ParseDocument accepted an array")
+	}
+	if _, err := ParseDocument([]byte(`{ "a": `)); err == nil {
+		t.Error("ParseDocument accepted broken json")
+	}
+
+	d := testDoc(t, testDocument)
+	if err := d.Set("x", "name", "nested"); err == nil {
+		t.Error("Set should fail when part of the path isn't an object")
+	}
+	if err := d.Set("x"); err == nil {
+		t.Error("Set should fail without a path")
+	}
+	expectDoc(t, d, testDocument)
+}
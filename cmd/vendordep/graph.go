@@ -0,0 +1,286 @@
+package vendordep
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	NodeVendordep = "vendordep"
+	NodeArtifact = "artifact"
+	NodeGroup = "group"
+	// a vendordep which is required but isn't installed
+	NodeMissing = "missing"
+)
+
+const (
+	EdgeJava = "java"
+	EdgeJni = "jni"
+	EdgeCpp = "cpp"
+	EdgeRequires = "requires"
+	// the vendordeps publish artifacts under the same groupId
+	EdgeShares = "shares"
+)
+
+type GraphNode struct {
+	ID string `json:"id"`
+	Kind string `json:"kind"`
+	Label string `json:"label"`
+	// Size in bytes of what ends up on the roboRIO, only known for artifacts
+	// which have been downloaded into the rph maven cache and the vendordeps
+	// using them
+	Size int64 `json:"size,omitempty"`
+}
+
+type GraphEdge struct {
+	From string `json:"from"`
+	To string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph how the installed vendordeps relate to each other and the maven
+// artifacts they pull in
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+func (g *Graph) node(n GraphNode) {
+	if !slices.ContainsFunc(g.Nodes, func(o GraphNode) bool { return o.ID == n.ID }) {
+		g.Nodes = append(g.Nodes, n)
+	}
+}
+
+func (g *Graph) edge(e GraphEdge) {
+	if !slices.Contains(g.Edges, e) {
+		g.Edges = append(g.Edges, e)
+	}
+}
+
+// vendordepId the id of a vendordep's node, vendordeps without a uuid are told
+// apart by their file name instead
+func vendordepId(dep Vendordep) string {
+	if dep.UUID == "" {
+		return NodeVendordep + ":file:" + dep.FileName
+	}
+	return NodeVendordep + ":" + dep.UUID
+}
+
+// robotSize add up the size of the roboRIO files for an artifact which are in
+// the maven repository at repoDir, poms and headers aren't deployed so they're
+// not counted
+func robotSize(dep *Vendordep, repoDir string, groupId, artifactId, version string) int64 {
+	var size int64
+	for _, a := range dep.Artifacts([]string{ RoboRioPlatform }, false) {
+		if a.GroupId != groupId || a.ArtifactId != artifactId || a.Version != version { continue }
+		if a.Extension == "pom" || strings.Contains(a.Classifier, "headers") { continue }
+
+		if info, err := os.Stat(filepath.Join(repoDir, filepath.FromSlash(a.Path()))); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// BuildGraph build a graph of deps, when repoDir is set sizes are filled in from
+// the artifacts found in the maven repository there
+func BuildGraph(deps []Vendordep, repoDir string) Graph {
+	var g Graph
+
+	// which vendordeps use each groupId so shared groups can be pointed out
+	groups := map[string][]string{}
+
+	for _, dep := range deps {
+		depId := vendordepId(dep)
+		node := GraphNode{ ID: depId, Kind: NodeVendordep, Label: dep.Name + " " + dep.Version }
+		// added after the vendordep so it comes first in the output
+		var artifacts []GraphNode
+
+		artifact := func(kind, groupId, artifactId, version string) {
+			id := NodeArtifact + ":" + groupId + ":" + artifactId + ":" + version
+			size := int64(0)
+			if repoDir != "" {
+				size = robotSize(&dep, repoDir, groupId, artifactId, version)
+			}
+
+			// the same artifact is often both a jni and c++ dependency, it's
+			// only counted once
+			if !slices.ContainsFunc(g.Edges, func(e GraphEdge) bool { return e.From == depId && e.To == id }) {
+				node.Size += size
+			}
+
+			artifacts = append(artifacts, GraphNode{ ID: id, Kind: NodeArtifact, Label: groupId + ":" + artifactId + ":" + version, Size: size })
+			g.edge(GraphEdge{ From: depId, To: id, Kind: kind })
+
+			if !slices.Contains(groups[groupId], depId) {
+				groups[groupId] = append(groups[groupId], depId)
+			}
+		}
+
+		for _, d := range dep.JavaDependencies {
+			artifact(EdgeJava, d.GroupId, d.ArtifactId, d.Version)
+		}
+		for _, d := range dep.JniDependencies {
+			artifact(EdgeJni, d.GroupId, d.ArtifactId, d.Version)
+		}
+		for _, d := range dep.CppDependencies {
+			artifact(EdgeCpp, d.GroupId, d.ArtifactId, d.Version)
+		}
+
+		g.node(node)
+		for _, a := range artifacts {
+			g.node(a)
+		}
+	}
+
+	for _, dep := range deps {
+		for _, r := range dep.Requires {
+			var to string
+			i := slices.IndexFunc(deps, func(d Vendordep) bool {
+				if r.UUID != "" {
+					return d.UUID == r.UUID
+				}
+				return r.OfflineFileName != "" && d.FileName == r.OfflineFileName
+			})
+			if i >= 0 {
+				to = vendordepId(deps[i])
+			} else {
+				to = NodeMissing + ":" + r.UUID
+				if r.UUID == "" {
+					to = NodeMissing + ":file:" + r.OfflineFileName
+				}
+				g.node(GraphNode{ ID: to, Kind: NodeMissing, Label: requirementName(r.OfflineFileName, r.UUID) })
+			}
+			g.edge(GraphEdge{ From: vendordepId(dep), To: to, Kind: EdgeRequires })
+		}
+	}
+
+	groupIds := make([]string, 0, len(groups))
+	for groupId := range groups {
+		groupIds = append(groupIds, groupId)
+	}
+	slices.Sort(groupIds)
+
+	for _, groupId := range groupIds {
+		if len(groups[groupId]) < 2 { continue }
+
+		id := NodeGroup + ":" + groupId
+		g.node(GraphNode{ ID: id, Kind: NodeGroup, Label: groupId })
+		for _, depId := range groups[groupId] {
+			g.edge(GraphEdge{ From: depId, To: id, Kind: EdgeShares })
+		}
+	}
+
+	return g
+}
+
+// FormatSize a human readable file size
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size) / float64(div), "KMGTPE"[exp])
+}
+
+func (n GraphNode) label() string {
+	if n.Size > 0 {
+		return n.Label + "\n" + FormatSize(n.Size)
+	}
+	return n.Label
+}
+
+func (e GraphEdge) label() string {
+	if e.Kind == EdgeShares {
+		return "shared artifacts"
+	}
+	return e.Kind
+}
+
+// WriteDOT write the graph in graphviz's dot format
+func (g Graph) WriteDOT(w io.Writer) {
+	shapes := map[string]string{
+		NodeVendordep: "box",
+		NodeArtifact: "ellipse",
+		NodeGroup: "folder",
+		NodeMissing: "box, style=dashed",
+	}
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+
+	fmt.Fprintln(w, "digraph vendordeps {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(w, "  %s [label=%s, shape=%s];\n", quote(n.ID), quote(n.label()), shapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if e.Kind == EdgeRequires || e.Kind == EdgeShares {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(w, "  %s -> %s [label=%s%s];\n", quote(e.From), quote(e.To), quote(e.label()), style)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// WriteJSON write the nodes and edges as json, an empty graph has empty lists
+// rather than nulls
+func (g Graph) WriteJSON(w io.Writer) error {
+	if g.Nodes == nil { g.Nodes = []GraphNode{} }
+	if g.Edges == nil { g.Edges = []GraphEdge{} }
+
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// WriteMermaid write the graph as a mermaid flowchart
+func (g Graph) WriteMermaid(w io.Writer) {
+	// mermaid ids can't have most punctuation in them
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`"`, "#quot;", "\n", "<br>").Replace(s) + `"`
+	}
+
+	fmt.Fprintln(w, "graph LR")
+	for _, n := range g.Nodes {
+		label := quote(n.label())
+		switch n.Kind {
+		case NodeArtifact:
+			label = "([" + label + "])"
+		case NodeGroup:
+			label = "[/" + label + "/]"
+		case NodeMissing:
+			label = "{{" + label + "}}"
+		default:
+			label = "[" + label + "]"
+		}
+		fmt.Fprintf(w, "  %s%s\n", ids[n.ID], label)
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == EdgeRequires || e.Kind == EdgeShares {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(w, "  %s %s|%s| %s\n", ids[e.From], arrow, e.label(), ids[e.To])
+	}
+}
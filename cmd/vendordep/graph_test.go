@@ -0,0 +1,229 @@
+package vendordep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func graphTestDeps() []Vendordep {
+	return []Vendordep{
+		{
+			FileName: "REVLib.json",
+			Name: "REVLib",
+			Version: "2025.0.3",
+			UUID: testUUID,
+			JavaDependencies: []JavaDepedency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-java", Version: "2025.0.3" },
+			},
+			JniDependencies: []JniDependency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", ValidPlatforms: []string{ RoboRioPlatform } },
+			},
+			CppDependencies: []CppDependency{
+				// the same artifact as the jni dependency
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-driver", Version: "2025.0.3", BinaryPlatforms: []string{ RoboRioPlatform } },
+			},
+		},
+		{
+			FileName: "REVLib-addon.json",
+			Name: "REV Addon",
+			Version: "1.0.0",
+			// no uuid, it's told apart by its file name
+			JavaDependencies: []JavaDepedency{
+				{ GroupId: "com.revrobotics.frc", ArtifactId: "addon-java", Version: "1.0.0" },
+			},
+			Requires: []Requirement{
+				{ UUID: testUUID, OfflineFileName: "REVLib.json" },
+				{ UUID: otherUUID, OfflineFileName: "Phoenix6.json" },
+			},
+		},
+		{
+			FileName: "Local.json",
+			Name: `My "Local" Lib`,
+			Version: "0.1.0",
+			Requires: []Requirement{
+				// no uuid so the file name is used to find it
+				{ OfflineFileName: "REVLib-addon.json" },
+				{ OfflineFileName: "Missing.json" },
+			},
+		},
+	}
+}
+
+func findNode(g Graph, id string) *GraphNode {
+	i := slices.IndexFunc(g.Nodes, func(n GraphNode) bool { return n.ID == id })
+	if i < 0 {
+		return nil
+	}
+	return &g.Nodes[i]
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := BuildGraph(graphTestDeps(), "")
+
+	rev := "vendordep:" + testUUID
+	addon := "vendordep:file:REVLib-addon.json"
+	local := "vendordep:file:Local.json"
+	driver := "artifact:com.revrobotics.frc:REVLib-driver:2025.0.3"
+	group := "group:com.revrobotics.frc"
+
+	for id, kind := range map[string]string{
+		rev: NodeVendordep,
+		addon: NodeVendordep,
+		local: NodeVendordep,
+		driver: NodeArtifact,
+		group: NodeGroup,
+		"missing:" + otherUUID: NodeMissing,
+		"missing:file:Missing.json": NodeMissing,
+	} {
+		n := findNode(g, id)
+		if n == nil {
+			t.Errorf("graph has no node %s", id)
+		} else if n.Kind != kind {
+			t.Errorf("node %s is a %s, want a %s", id, n.Kind, kind)
+		}
+	}
+	if n := findNode(g, "missing:file:Missing.json"); n != nil && n.Label != "Missing.json" {
+		t.Errorf("missing requirement is labelled %q", n.Label)
+	}
+
+	want := []GraphEdge{
+		{ From: rev, To: driver, Kind: EdgeJni },
+		{ From: rev, To: driver, Kind: EdgeCpp },
+		{ From: addon, To: rev, Kind: EdgeRequires },
+		{ From: addon, To: "missing:" + otherUUID, Kind: EdgeRequires },
+		{ From: local, To: addon, Kind: EdgeRequires },
+		{ From: local, To: "missing:file:Missing.json", Kind: EdgeRequires },
+		{ From: rev, To: group, Kind: EdgeShares },
+		{ From: addon, To: group, Kind: EdgeShares },
+	}
+	for _, e := range want {
+		if !slices.Contains(g.Edges, e) {
+			t.Errorf("graph has no edge %+v", e)
+		}
+	}
+
+	// a group used by one vendordep isn't worth pointing out
+	if n := findNode(g, "group:"); n != nil {
+		t.Errorf("graph has a group for a vendordep without artifacts: %+v", n)
+	}
+	if len(g.Nodes) != 9 {
+		t.Errorf("graph has %d nodes, want 9: %+v", len(g.Nodes), g.Nodes)
+	}
+}
+
+func TestBuildGraphSizes(t *testing.T) {
+	repo := t.TempDir()
+	deps := graphTestDeps()[:1]
+
+	for _, a := range deps[0].Artifacts([]string{ RoboRioPlatform }, false) {
+		if a.Extension == "pom" { continue }
+		p := filepath.Join(repo, filepath.FromSlash(a.Path()))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, 1000), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := BuildGraph(deps, repo)
+	driver := findNode(g, "artifact:com.revrobotics.frc:REVLib-driver:2025.0.3")
+	rev := findNode(g, "vendordep:" + testUUID)
+	if driver == nil || rev == nil {
+		t.Fatalf("graph is missing nodes: %+v", g.Nodes)
+	}
+	if driver.Size == 0 {
+		t.Error("the driver's size wasn't found")
+	}
+
+	// the driver is a jni and a c++ dependency but it's only deployed once
+	java := findNode(g, "artifact:com.revrobotics.frc:REVLib-java:2025.0.3")
+	if rev.Size != driver.Size + java.Size {
+		t.Errorf("REVLib is %d bytes, want %d + %d", rev.Size, driver.Size, java.Size)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	BuildGraph(graphTestDeps(), "").WriteDOT(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"digraph vendordeps {",
+		`"vendordep:file:Local.json" [label="My \"Local\" Lib 0.1.0", shape=box];`,
+		`"vendordep:file:REVLib-addon.json" [label="REV Addon 1.0.0", shape=box];`,
+		`"missing:file:Missing.json" [label="Missing.json", shape=box, style=dashed];`,
+		`"vendordep:file:REVLib-addon.json" -> "group:com.revrobotics.frc" [label="shared artifacts", style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dot output is missing %s:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Errorf("dot output isn't closed:\n%s", out)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	g := BuildGraph(graphTestDeps(), "")
+	g.WriteMermaid(&buf)
+	out := buf.String()
+
+	id := func(nodeId string) string {
+		i := slices.IndexFunc(g.Nodes, func(n GraphNode) bool { return n.ID == nodeId })
+		return fmt.Sprintf("n%d", i)
+	}
+
+	for _, want := range []string{
+		"graph LR",
+		id("vendordep:file:Local.json") + `["My #quot;Local#quot; Lib 0.1.0"]`,
+		id("vendordep:file:REVLib-addon.json") + `["REV Addon 1.0.0"]`,
+		id("missing:file:Missing.json") + `{{"Missing.json"}}`,
+		id("group:com.revrobotics.frc") + `[/"com.revrobotics.frc"/]`,
+		id("vendordep:file:Local.json") + " -.->|requires| " + id("vendordep:file:REVLib-addon.json"),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("mermaid output is missing %s:\n%s", want, out)
+		}
+	}
+
+	// ids are only letters and numbers, whatever is in the vendordep
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+		node := strings.Fields(line)[0]
+		node = node[:strings.IndexAny(node + "[({", "[({")]
+		if strings.Trim(node, "n0123456789") != "" {
+			t.Errorf("mermaid id %q has punctuation in it", node)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (Graph{}).WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(strings.Fields(buf.String()), "") != `{"nodes":[],"edges":[]}` {
+		t.Errorf("empty graph = %s", buf.String())
+	}
+
+	buf.Reset()
+	g := BuildGraph(graphTestDeps(), "")
+	if err := g.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Graph
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(decoded.Nodes, g.Nodes) || !slices.Equal(decoded.Edges, g.Edges) {
+		t.Errorf("json doesn't round trip:\n%s", buf.String())
+	}
+}
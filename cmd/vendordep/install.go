@@ -0,0 +1,173 @@
+package vendordep
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// validFileName check a vendordep file name can be written into the vendordeps
+// directory. File names come from whoever made the vendordep or the lockfile,
+// don't let them put the file anywhere else.
+func validFileName(name string) bool {
+	return name != "" &&
+		filepath.Base(name) == name &&
+		!strings.ContainsAny(name, `/\`) &&
+		strings.HasSuffix(name, ".json")
+}
+
+// Install write a vendordep into a project. The vendordep is also cached so it
+// can be installed offline later and recorded in the project's lockfile along
+// with the version constraint it was installed with, if any.
+func Install(projectDir string, data []byte, source string, constraint string) (*Vendordep, error) {
+	dep, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if !validFileName(dep.FileName) {
+		slog.Error("Vendordep has an invalid file name", "name", dep.Name, "fileName", dep.FileName)
+		return nil, errors.New("invalid vendordep fileName: " + dep.FileName)
+	}
+
+	return installFile(projectDir, dep.FileName, data, source, constraint)
+}
+
+// installFile install a vendordep under a specific file name
+func installFile(projectDir string, fileName string, data []byte, source string, constraint string) (*Vendordep, error) {
+	dep, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Join(projectDir, vendordepDir), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(projectDir, vendordepDir, fileName), data, 0644)
+	if err != nil {
+		slog.Error("Failed to write vendordep", "file", fileName, "error", err)
+		return nil, err
+	}
+
+	err = Cache(data, dep)
+	if err != nil {
+		slog.Warn("Failed to cache vendordep", "name", dep.Name, "error", err)
+	}
+
+	lock, err := LoadLock(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	entry := NewLockEntry(dep, data, source)
+	entry.FileName = fileName
+	entry.Constraint = constraint
+	lock.Set(entry)
+
+	return dep, lock.Save(projectDir)
+}
+
+// Replace install a new version of an installed vendordep. A copy of the old
+// vendordep goes into the trash first so the update can be undone, when the
+// install fails the old vendordep is put back the way it was.
+func Replace(projectDir string, old Vendordep, data []byte, source string, constraint string) (*Vendordep, error) {
+	path := filepath.Join(projectDir, vendordepDir, old.FileName)
+	oldData, err := os.ReadFile(path)
+	if err != nil {
+		slog.Error("Failed to read installed vendordep", "file", old.FileName, "error", err)
+		return nil, err
+	}
+
+	trashed, err := TrashCopy(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dep, err := Install(projectDir, data, source, constraint)
+	if err != nil {
+		if err := os.WriteFile(path, oldData, 0644); err != nil {
+			slog.Error("Failed to put the old vendordep back, restore it from the trash", "id", trashed.ID, "error", err)
+			return nil, err
+		}
+		trashed.Delete()
+		return nil, err
+	}
+
+	// the old file is already in the trash, it only has to be removed when the
+	// new version didn't overwrite it
+	if dep.FileName != old.FileName {
+		err = Uninstall(projectDir, old, false)
+		if err != nil {
+			slog.Error("Failed to remove old vendordep", "file", old.FileName, "error", err)
+			return nil, err
+		}
+	}
+
+	return dep, nil
+}
+
+// Rewrite replace an installed vendordep with an edited copy of it. The lockfile
+// keeps track of where the vendordep originally came from and the edited copy is
+// cached so that sync can restore it.
+func Rewrite(projectDir string, dep Vendordep, data []byte) (*Vendordep, error) {
+	edited, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if edited.FileName != dep.FileName {
+		return nil, errors.New("the fileName of an installed vendordep can't be changed")
+	}
+
+	err = os.WriteFile(filepath.Join(projectDir, vendordepDir, dep.FileName), data, 0644)
+	if err != nil {
+		slog.Error("Failed to write vendordep", "file", dep.FileName, "error", err)
+		return nil, err
+	}
+
+	err = cacheEdited(data)
+	if err != nil {
+		slog.Warn("Failed to cache edited vendordep", "name", dep.Name, "error", err)
+	}
+
+	lock, err := LoadLock(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry := lock.Find(dep.FileName); entry != nil {
+		entry.Version = edited.Version
+		entry.Sha256 = Checksum(data)
+	} else {
+		lock.Set(NewLockEntry(edited, data, ""))
+	}
+
+	return edited, lock.Save(projectDir)
+}
+
+// Uninstall remove a vendordep from a project and it's lockfile, when trash is
+// set the vendordep is moved into the trash instead of being deleted.
+func Uninstall(projectDir string, dep Vendordep, trash bool) error {
+	path := filepath.Join(projectDir, vendordepDir, dep.FileName)
+
+	var err error
+	if trash {
+		err = Trash(path)
+	} else {
+		err = os.Remove(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	lock, err := LoadLock(projectDir)
+	if err != nil {
+		return err
+	}
+	lock.Remove(dep.FileName)
+
+	return lock.Save(projectDir)
+}
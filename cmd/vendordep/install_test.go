@@ -0,0 +1,67 @@
+package vendordep
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	dir := testProject(t)
+	old := writeVendordep(t, dir, "A", "1.0.0", "00000000-0000-0000-0000-000000000001")
+	installedDep, err := Install(dir, old, "https://example.com/A.json", "^1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newer := vendordepData("A", "1.1.0", "00000000-0000-0000-0000-000000000001")
+	dep, err := Replace(dir, *installedDep, newer, "https://example.com/A.json", "^1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep.Version != "1.1.0" {
+		t.Errorf("Replace installed %s", dep.Version)
+	}
+
+	// the old version can be restored from the trash
+	entries, err := ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Version != "1.0.0" || entries[0].Source != "https://example.com/A.json" {
+		t.Fatalf("trash = %+v, want the old version", entries)
+	}
+	if data, err := os.ReadFile(entries[0].Path()); err != nil || string(data) != string(old) {
+		t.Errorf("trashed file = %s, %v", data, err)
+	}
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := lock.Find("A.json"); e == nil || e.Version != "1.1.0" || e.Constraint != "^1.0.0" {
+		t.Errorf("lock entry = %+v", e)
+	}
+}
+
+func TestReplaceFailureKeepsOldVendordep(t *testing.T) {
+	dir := testProject(t)
+	old := writeVendordep(t, dir, "A", "1.0.0", "00000000-0000-0000-0000-000000000001")
+	installedDep, err := Install(dir, old, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bad := []byte(`{"fileName":"../A.json","name":"A","version":"2.0.0"}`)
+	if _, err := Replace(dir, *installedDep, bad, "", ""); err == nil {
+		t.Fatal("Replace installed a vendordep with an invalid file name")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, vendordepDir, "A.json"))
+	if err != nil || string(data) != string(old) {
+		t.Errorf("old vendordep wasn't kept: %s, %v", data, err)
+	}
+	if entries, _ := ListTrash(); len(entries) != 0 {
+		t.Errorf("a failed replace left %d entries in the trash", len(entries))
+	}
+}
@@ -0,0 +1,353 @@
+package vendordep
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const LockFileName = "rph.lock"
+
+// LockEntry everything needed to get back the exact same vendordep file
+type LockEntry struct {
+	FileName string `json:"fileName"`
+	Name string `json:"name"`
+	Version string `json:"version"`
+	UUID string `json:"uuid"`
+	Source string `json:"source"`
+	Sha256 string `json:"sha256"`
+	// Constraint the version constraint the vendordep was added with, update
+	// won't go outside of it
+	Constraint string `json:"constraint,omitempty"`
+
+	// seeded set when the entry was made from a vendordep which was already
+	// installed, its source is looked up when the lock is saved
+	seeded bool
+}
+
+// Lock the contents of vendordeps/rph.lock
+type Lock struct {
+	Vendordeps []LockEntry `json:"vendordeps"`
+	// whether the lock was read from disk, rather than made up from the
+	// vendordeps which happen to be installed
+	exists bool
+}
+
+// ErrNoLockFile the project doesn't have a lockfile yet
+var ErrNoLockFile = errors.New("the project has no " + LockFileName + ", add or update a vendordep to create one")
+
+// Checksum get the hex encoded sha256 of a vendordep file
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func NewLockEntry(dep *Vendordep, data []byte, source string) LockEntry {
+	return LockEntry{
+		FileName: dep.FileName,
+		Name: dep.Name,
+		Version: dep.Version,
+		UUID: dep.UUID,
+		Source: source,
+		Sha256: Checksum(data),
+	}
+}
+
+// LoadLock read the lockfile from a project. If the project doesn't have a
+// lockfile yet one is made from the vendordeps which are installed, so that the
+// first save doesn't leave any of them out. Nothing is written until the lock is
+// saved.
+func LoadLock(projectDir string) (*Lock, error) {
+	var lock Lock
+
+	data, err := os.ReadFile(filepath.Join(projectDir, vendordepDir, LockFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &lock, lock.seed(projectDir)
+	} else if err != nil {
+		slog.Error("Failed to read lockfile", "error", err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &lock); err != nil {
+		slog.Error("Failed to decode lockfile", "error", err)
+		return nil, err
+	}
+
+	for _, e := range lock.Vendordeps {
+		if !validFileName(e.FileName) {
+			slog.Error("Lockfile has an invalid file name", "name", e.Name, "fileName", e.FileName)
+			return nil, errors.New("invalid fileName in lockfile: " + e.FileName)
+		}
+	}
+
+	lock.exists = true
+	return &lock, nil
+}
+
+// seed lock every vendordep which is installed, where they came from is only
+// worked out once the lock is saved
+func (l *Lock) seed(projectDir string) error {
+	files, err := installedFiles(projectDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(projectDir, vendordepDir, f))
+		if err != nil {
+			return err
+		}
+
+		dep, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			slog.Warn("Not locking vendordep which can't be parsed", "file", f, "error", err)
+			continue
+		}
+
+		entry := NewLockEntry(dep, data, "")
+		entry.FileName = f
+		entry.seeded = true
+		l.Set(entry)
+	}
+
+	return nil
+}
+
+// findSource work out where a vendordep which was already installed came from,
+// its jsonUrl or the marketplace are only used when they have the exact same
+// file. The vendordep is cached as well so this machine can always restore it.
+func findSource(projectDir string, e LockEntry, online func(year string) map[string][]OnlineVendordep) string {
+	data, err := os.ReadFile(filepath.Join(projectDir, vendordepDir, e.FileName))
+	if err != nil {
+		return ""
+	}
+	dep, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	Cache(data, dep)
+
+	if dep.JsonUrl != "" {
+		remote, _, err := FetchVendorDep(dep.JsonUrl)
+		if err == nil && Checksum(remote) == e.Sha256 {
+			return dep.JsonUrl
+		}
+	}
+
+	if dep.FrcYear == "" {
+		return ""
+	}
+	for _, deps := range online(string(dep.FrcYear)) {
+		for _, o := range deps {
+			if !o.Matches(*dep) { continue }
+
+			remote, _, url, err := o.Fetch()
+			if err == nil && Checksum(remote) == e.Sha256 {
+				return url
+			}
+		}
+	}
+
+	slog.Warn("Unable to find where vendordep came from, sync can only restore it from this machine's cache", "file", e.FileName)
+	return ""
+}
+
+func (l *Lock) Save(projectDir string) error {
+	// the marketplace is only listed once for each year, and only if it's
+	// needed
+	marketplace := map[string]map[string][]OnlineVendordep{}
+	online := func(year string) map[string][]OnlineVendordep {
+		if deps, ok := marketplace[year]; ok {
+			return deps
+		}
+		deps, _ := ListAvailableOnlineDeps(year)
+		marketplace[year] = deps
+		return deps
+	}
+
+	for i, e := range l.Vendordeps {
+		if !e.seeded || e.Source != "" { continue }
+
+		l.Vendordeps[i].Source = findSource(projectDir, e, online)
+		l.Vendordeps[i].seeded = false
+	}
+
+	slices.SortFunc(l.Vendordeps, func(a, b LockEntry) int {
+		return strings.Compare(a.FileName, b.FileName)
+	})
+
+	// constraints like <2025.3 would be escaped otherwise
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(l); err != nil {
+		slog.Error("Failed to encode lockfile", "error", err)
+		return err
+	}
+
+	err := os.WriteFile(filepath.Join(projectDir, vendordepDir, LockFileName), buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+
+	l.exists = true
+	return nil
+}
+
+func (l *Lock) Find(fileName string) *LockEntry {
+	for i, e := range l.Vendordeps {
+		if e.FileName == fileName {
+			return &l.Vendordeps[i]
+		}
+	}
+
+	return nil
+}
+
+// Set add an entry to the lock, replacing any entry for the same file
+func (l *Lock) Set(entry LockEntry) {
+	if e := l.Find(entry.FileName); e != nil {
+		*e = entry
+		return
+	}
+
+	l.Vendordeps = append(l.Vendordeps, entry)
+}
+
+func (l *Lock) Remove(fileName string) {
+	l.Vendordeps = slices.DeleteFunc(l.Vendordeps, func(e LockEntry) bool {
+		return e.FileName == fileName
+	})
+}
+
+// installedFiles list the vendordep files in a project
+func installedFiles(projectDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(projectDir, vendordepDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, e.Name())
+		}
+	}
+
+	return files, nil
+}
+
+// Verify compare the vendordeps on disk against the lock, every difference is
+// returned as an error
+func (l *Lock) Verify(projectDir string) ([]error, error) {
+	if !l.exists {
+		return nil, ErrNoLockFile
+	}
+
+	var drift []error
+
+	for _, e := range l.Vendordeps {
+		data, err := os.ReadFile(filepath.Join(projectDir, vendordepDir, e.FileName))
+		if errors.Is(err, os.ErrNotExist) {
+			drift = append(drift, fmt.Errorf("%s is locked but not installed", e.FileName))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if Checksum(data) != e.Sha256 {
+			drift = append(drift, fmt.Errorf("%s does not match the locked checksum", e.FileName))
+		}
+	}
+
+	files, err := installedFiles(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		if l.Find(f) == nil {
+			drift = append(drift, fmt.Errorf("%s is installed but not locked", f))
+		}
+	}
+
+	return drift, nil
+}
+
+// Sync make the vendordeps on disk match the lock. Locked files are taken from
+// the cache when possible and otherwise downloaded from their source. When
+// prune is set any vendordep which isn't locked is moved into the trash.
+func (l *Lock) Sync(projectDir string, prune bool) error {
+	if !l.exists {
+		return ErrNoLockFile
+	}
+
+	for _, e := range l.Vendordeps {
+		path := filepath.Join(projectDir, vendordepDir, e.FileName)
+
+		data, err := os.ReadFile(path)
+		if err == nil && Checksum(data) == e.Sha256 {
+			continue
+		}
+
+		data, err = findCachedChecksum(e.Sha256)
+		if err != nil && e.Source == "" {
+			slog.Error("Vendordep is not cached and the lockfile doesn't know where it came from", "name", e.Name)
+			return errors.New(e.FileName + " is not cached and has no source to download it from, add it again with rph vendordep add to record one")
+		} else if err != nil {
+			slog.Info("Vendordep is not cached, downloading it", "name", e.Name, "url", e.Source)
+
+			var dep *Vendordep
+			data, dep, err = FetchVendorDep(e.Source)
+			if err != nil {
+				return err
+			}
+			if Checksum(data) != e.Sha256 {
+				slog.Error("Downloaded vendordep does not match the lockfile", "name", e.Name, "url", e.Source)
+				return errors.New("checksum mismatch for " + e.FileName)
+			}
+
+			Cache(data, dep)
+		}
+
+		err = os.WriteFile(path, data, 0644)
+		if err != nil {
+			slog.Error("Failed to write vendordep", "file", e.FileName, "error", err)
+			return err
+		}
+		slog.Info("Restored vendordep", "name", e.Name, "version", e.Version)
+	}
+
+	files, err := installedFiles(projectDir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if l.Find(f) != nil { continue }
+
+		if !prune {
+			slog.Warn("Vendordep isn't locked, use --prune to remove it", "file", f)
+			continue
+		}
+
+		err = Trash(filepath.Join(projectDir, vendordepDir, f))
+		if err != nil {
+			return err
+		}
+		slog.Info("Removed vendordep which isn't locked", "file", f)
+	}
+
+	return nil
+}
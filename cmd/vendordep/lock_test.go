@@ -0,0 +1,325 @@
+package vendordep
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rph/state"
+	"strings"
+	"testing"
+)
+
+// testProject make a project with the given vendordeps installed and an empty
+// rph cache for it to use, the marketplace is empty
+func testProject(t *testing.T, names ...string) string {
+	t.Helper()
+
+	state.CachePath = t.TempDir()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	urls := ArtifactoryUrls
+	t.Cleanup(func() { ArtifactoryUrls = urls })
+	ArtifactoryUrls = []string{ server.URL }
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, vendordepDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, name := range names {
+		writeVendordep(t, dir, name, "1.0.0", fmt.Sprintf("00000000-0000-0000-0000-%012d", i + 1))
+	}
+	return dir
+}
+
+func writeVendordep(t *testing.T, dir string, name string, version string, uuid string) []byte {
+	t.Helper()
+
+	data := vendordepData(name, version, uuid)
+	if err := os.WriteFile(filepath.Join(dir, vendordepDir, name + ".json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// vendordepData the smallest vendordep file with the given name, version and
+// uuid
+func vendordepData(name string, version string, uuid string) []byte {
+	return fmt.Appendf(nil, `{
+  "fileName": "%s.json",
+  "name": "%s",
+  "version": "%s",
+  "frcYear": "2025",
+  "uuid": "%s",
+  "mavenUrls": [],
+  "jsonUrl": "",
+  "javaDependencies": [],
+  "jniDependencies": [],
+  "cppDependencies": []
+}
+`, name, name, version, uuid)
+}
+
+func installed(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := installedFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestLoadLockWithoutLockfile(t *testing.T) {
+	dir := testProject(t, "A", "B")
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// everything installed is locked so the first save keeps it
+	if lock.Find("A.json") == nil || lock.Find("B.json") == nil {
+		t.Errorf("lock wasn't seeded from the installed vendordeps: %+v", lock.Vendordeps)
+	}
+
+	if _, err := lock.Verify(dir); !errors.Is(err, ErrNoLockFile) {
+		t.Errorf("Verify without a lockfile = %v, want ErrNoLockFile", err)
+	}
+	if err := lock.Sync(dir, true); !errors.Is(err, ErrNoLockFile) {
+		t.Errorf("Sync without a lockfile = %v, want ErrNoLockFile", err)
+	}
+	if files := installed(t, dir); len(files) != 2 {
+		t.Errorf("Sync without a lockfile touched the vendordeps: %v", files)
+	}
+
+	// loading is read only, nothing is written until the lock is saved
+	if _, err := os.Stat(filepath.Join(dir, vendordepDir, LockFileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadLock wrote a lockfile: %v", err)
+	}
+	if entries, err := os.ReadDir(state.CachePath); err != nil || len(entries) != 0 {
+		t.Errorf("LoadLock wrote to the cache: %v, %v", entries, err)
+	}
+}
+
+func TestLockSaveFindsSources(t *testing.T) {
+	dir := testProject(t)
+
+	files := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(files[r.URL.Path])
+	}))
+	t.Cleanup(server.Close)
+
+	withJsonUrl := func(name string) []byte {
+		return fmt.Appendf(nil, `{"fileName":"%s.json","name":"%s","version":"1.0.0","jsonUrl":"%s/%s.json"}`, name, name, server.URL, name)
+	}
+
+	// A's jsonUrl has the same file, B's has been changed since it was
+	// installed
+	files["/A.json"] = withJsonUrl("A")
+	files["/B.json"] = []byte(`{"fileName":"B.json","name":"B","version":"2.0.0"}`)
+	for _, name := range []string{ "A", "B" } {
+		if err := os.WriteFile(filepath.Join(dir, vendordepDir, name + ".json"), withJsonUrl(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if e := lock.Find("A.json"); e.Source != server.URL + "/A.json" {
+		t.Errorf("A was locked with the source %q, want its jsonUrl", e.Source)
+	}
+	if e := lock.Find("B.json"); e.Source != "" {
+		t.Errorf("B was locked with the source %q, its jsonUrl has a different file", e.Source)
+	}
+
+	// both are cached so this machine can restore them
+	for _, name := range []string{ "A", "B" } {
+		if _, err := findCachedChecksum(lock.Find(name + ".json").Sha256); err != nil {
+			t.Errorf("%s wasn't cached when it was locked: %v", name, err)
+		}
+	}
+}
+
+func TestLoadLockRejectsInvalidFileNames(t *testing.T) {
+	for _, name := range []string{ "../../.bashrc", "sub/A.json", `..\A.json`, "A.txt", "" } {
+		dir := testProject(t)
+
+		lock := fmt.Sprintf(`{"vendordeps":[{"fileName":%q,"name":"A","sha256":"x"}]}`, name)
+		if err := os.WriteFile(filepath.Join(dir, vendordepDir, LockFileName), []byte(lock), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadLock(dir); err == nil {
+			t.Errorf("LoadLock accepted the file name %q", name)
+		}
+	}
+}
+
+func TestLockSaveRoundTrip(t *testing.T) {
+	dir := testProject(t, "A")
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Find("A.json").Constraint = "<2.0.0"
+	if err := lock.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, vendordepDir, LockFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"constraint": "<2.0.0"`) {
+		t.Errorf("constraint wasn't written as is:\n%s", data)
+	}
+
+	loaded, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := loaded.Find("A.json"); e == nil || e.Constraint != "<2.0.0" || e.Sha256 != lock.Find("A.json").Sha256 {
+		t.Errorf("lock didn't survive a round trip: %+v", loaded.Vendordeps)
+	}
+}
+
+func TestLockVerify(t *testing.T) {
+	dir := testProject(t, "A", "B")
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err := lock.Verify(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("a freshly locked project has drifted: %v", drift)
+	}
+
+	writeVendordep(t, dir, "A", "1.0.1", "00000000-0000-0000-0000-000000000001")
+	os.Remove(filepath.Join(dir, vendordepDir, "B.json"))
+	writeVendordep(t, dir, "C", "1.0.0", "00000000-0000-0000-0000-000000000003")
+
+	drift, err = lock.Verify(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"A.json does not match the locked checksum",
+		"B.json is locked but not installed",
+		"C.json is installed but not locked",
+	}
+	if len(drift) != len(want) {
+		t.Fatalf("Verify = %v, want %v", drift, want)
+	}
+	for i, err := range drift {
+		if err.Error() != want[i] {
+			t.Errorf("Verify()[%d] = %q, want %q", i, err, want[i])
+		}
+	}
+}
+
+func TestLockSync(t *testing.T) {
+	dir := testProject(t, "A", "B")
+
+	original, err := os.ReadFile(filepath.Join(dir, vendordepDir, "A.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// A is edited and B is removed, both come back out of the cache
+	writeVendordep(t, dir, "A", "9.9.9", "00000000-0000-0000-0000-000000000001")
+	os.Remove(filepath.Join(dir, vendordepDir, "B.json"))
+	writeVendordep(t, dir, "C", "1.0.0", "00000000-0000-0000-0000-000000000003")
+
+	if err := lock.Sync(dir, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, vendordepDir, "A.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("A.json wasn't restored:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, vendordepDir, "B.json")); err != nil {
+		t.Errorf("B.json wasn't restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, vendordepDir, "C.json")); err != nil {
+		t.Errorf("C.json was removed without prune: %v", err)
+	}
+
+	if err := lock.Sync(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, vendordepDir, "C.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("C.json wasn't pruned: %v", err)
+	}
+
+	trash, err := ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].FileName != "C.json" {
+		t.Errorf("pruned vendordep isn't in the trash: %+v", trash)
+	}
+
+	drift, err := lock.Verify(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("project has drifted after a sync: %v", drift)
+	}
+}
+
+func TestLockSyncWithoutSource(t *testing.T) {
+	dir := testProject(t, "A")
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// with nothing cached and no source there's nowhere to get A from
+	if err := os.RemoveAll(state.CachePath); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, vendordepDir, "A.json"))
+
+	if err := lock.Sync(dir, false); err == nil {
+		t.Error("Sync should fail when a vendordep can't be found")
+	}
+}
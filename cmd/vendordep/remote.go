@@ -1,52 +1,101 @@
 package vendordep
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"io/fs"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"rph/cmd/vendordep/artifactory"
+	"strings"
 	"time"
 )
 
+const marketplacePath = "vendordeps/vendordep-marketplace/"
+
+// breaks a vendordep file name into "name-of-library" and "2025.9.28", the
+// version may have a "v" prefix, up to four parts and a pre-release tag
+var fileNameRe = regexp.MustCompile(`^(.+?)-[vV]?(\d+(?:\.\d+){1,3}(?:-[0-9A-Za-z.-]+?)?)(?:\.json)?$`)
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ArtifactoryUrls the artifactory instances which host the vendordep
+// marketplace, in the order they should be tried
+var ArtifactoryUrls = []string{ artifactory.DefaultVendorDepArtifactoryUrl }
+
+// ResponseCache where responses from the artifactory are cached, nil turns
+// caching off
+var ResponseCache *artifactory.Cache
+
+// Timeout how long to wait for an artifactory to respond
+var Timeout = artifactory.DefaultTimeout
+
+// marketplace the artifactories hosting the vendordep marketplace
+func marketplace() artifactory.ArtifactoryFS {
+	fsys := artifactory.New(ArtifactoryUrls...)
+	fsys.Cache = ResponseCache
+	fsys.Timeout = Timeout
+	return fsys
+}
+
 type OnlineVendordep struct {
 	VendordepName string
 	Version string
 	FileName string
+	Year string
 	LastModTime time.Time
 }
 
-func ListAvailableOnlineDeps(year string) (map[string][]OnlineVendordep, error) {
-	fsys := artifactory.New(artifactory.DefaultVendorDepArtifactoryUrl)
-	path := "vendordeps/vendordep-marketplace/" + year
+// Url get the url which the vendordep file can be downloaded from on the first
+// artifactory, Fetch should be preferred as it'll fall back to the others
+func (d OnlineVendordep) Url() string {
+	fsys := artifactory.New(ArtifactoryUrls...)
+	return fsys.GetUrl(marketplacePath + d.Year + "/" + d.FileName)
+}
 
-	entries, err := fs.ReadDir(fsys, path)
+// Stat fill in LastModTime from the artifactory
+func (d *OnlineVendordep) Stat() error {
+	info, err := fs.Stat(marketplace(), marketplacePath + d.Year + "/" + d.FileName)
 	if err != nil {
-		slog.Error("Failed to readdir from artifactory", "dir", path, "error", err)
-		return nil, err
+		return err
 	}
 
-	type fileEntry struct {
-		Name string
-		ModTime time.Time
-	}
+	d.LastModTime = info.ModTime()
+	return nil
+}
 
-	files := make([]fileEntry, len(entries))
-	for i, e := range entries {
-		info, err := e.Info()
-		if err != nil {
-			return nil, err
-		}
+// Matches check if an installed vendordep is this version of this vendordep
+func (d OnlineVendordep) Matches(dep Vendordep) bool {
+	return onlineNameMatches(dep, d.VendordepName) && sameVersion(dep.Version, d.Version)
+}
+
+// SortVersions sort vendordeps from the newest version to the oldest
+func SortVersions(deps []OnlineVendordep) {
+	slices.SortStableFunc(deps, func(a, b OnlineVendordep) int {
+		return compareVersions(b.Version, a.Version)
+	})
+}
 
-		files[i] = fileEntry{ Name: e.Name(), ModTime: info.ModTime() }
+func ListAvailableOnlineDeps(year string) (map[string][]OnlineVendordep, error) {
+	fsys := marketplace()
+	path := marketplacePath + year
+
+	entries, err := fs.ReadDir(fsys, path)
+	if err != nil {
+		slog.Error("Failed to readdir from artifactory", "dir", path, "error", err)
+		return nil, err
 	}
 
 	allDeps := make(map[string][]OnlineVendordep, len(entries))
 
-	// breaks a vendordep file name into "name-of-library" and "v2025.9.28"
-	re := regexp.MustCompile(`^(.+)-v?(\d+\.\d+(?:\.\d+)?)`)
-	for _, file := range files {
-		matches := re.FindStringSubmatch(file.Name)
+	// the entries aren't stat'd, that'd be a request for every file in the
+	// marketplace. Use Stat to get LastModTime when it's needed.
+	for _, e := range entries {
+		matches := fileNameRe.FindStringSubmatch(e.Name())
 		if len(matches) > 2 {
 			baseName := matches[1]
 			version := matches[2]
@@ -54,13 +103,196 @@ func ListAvailableOnlineDeps(year string) (map[string][]OnlineVendordep, error)
 			allDeps[baseName] = append(allDeps[baseName], OnlineVendordep{
 				VendordepName: baseName,
 				Version: version,
-				FileName: file.Name,
-				LastModTime: file.ModTime,
+				FileName: e.Name(),
+				Year: year,
 			})
 		} else {
-			return nil, errors.New("Vendordep file name does not match format '" + file.Name + "'")
+			slog.Warn("Skipping vendordep with an unknown file name format", "file", e.Name(), "year", year)
 		}
 	}
 
+	for _, deps := range allDeps {
+		SortVersions(deps)
+	}
+
 	return allDeps, nil
 }
+
+// Latest get the newest version out of deps, releases are always preferred over
+// pre-releases unless there are only pre-releases.
+func Latest(deps []OnlineVendordep) *OnlineVendordep {
+	var latest *OnlineVendordep
+	for i, d := range deps {
+		if latest == nil {
+			latest = &deps[i]
+			continue
+		}
+
+		if isPreRelease(latest.Version) != isPreRelease(d.Version) {
+			if isPreRelease(latest.Version) {
+				latest = &deps[i]
+			}
+			continue
+		}
+
+		if compareVersions(latest.Version, d.Version) < 0 {
+			latest = &deps[i]
+		}
+	}
+
+	return latest
+}
+
+// FindAvailableOnlineDep find a vendordep on the marketplace for year by the
+// name it's listed under, e.g. "photonlib-2025.3.1". When no version is given
+// the latest version is used.
+func FindAvailableOnlineDep(year string, name string) (*OnlineVendordep, error) {
+	online, err := ListAvailableOnlineDeps(year)
+	if err != nil {
+		return nil, err
+	}
+
+	query := ParseQuery(name)
+	for k, deps := range online {
+		if !strings.EqualFold(k, query.Name) && !strings.EqualFold(k, name) { continue }
+
+		if query.Version == "" || strings.EqualFold(k, name) {
+			return Latest(deps), nil
+		}
+
+		for _, dep := range deps {
+			if sameVersion(dep.Version, query.Version) {
+				return &dep, nil
+			}
+		}
+	}
+
+	return nil, errors.New("Vendordep not found on the marketplace")
+}
+
+// ResolveOnlineDep find the newest version of a vendordep on the marketplace for
+// year which satisfies c, releases are preferred over pre-releases.
+func ResolveOnlineDep(year string, name string, c Constraint) (*OnlineVendordep, error) {
+	deps, err := onlineVersions(year, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(deps) == 0 {
+		return nil, errors.New("Vendordep not found on the marketplace")
+	}
+
+	var allowed []OnlineVendordep
+	for _, dep := range deps {
+		if c.Allows(dep.Version) {
+			allowed = append(allowed, dep)
+		}
+	}
+
+	if latest := Latest(allowed); latest != nil {
+		return latest, nil
+	}
+	return nil, errors.New("No version of " + name + " matches " + c.String())
+}
+
+// Fetch download and parse a vendordep from the marketplace, the url of the
+// artifactory which actually served the file is returned as well.
+func (d OnlineVendordep) Fetch() ([]byte, *Vendordep, string, error) {
+	fsys := marketplace()
+
+	file, err := fsys.Open(marketplacePath + d.Year + "/" + d.FileName)
+	if err != nil {
+		slog.Error("Failed to download vendordep", "file", d.FileName, "error", err)
+		return nil, nil, "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		slog.Error("Failed to read vendordep", "file", d.FileName, "error", err)
+		return nil, nil, "", err
+	}
+
+	url := d.Url()
+	if info, err := file.Stat(); err == nil {
+		if meta, ok := info.Sys().(*artifactory.FileMeta); ok {
+			url = meta.URL
+
+			if err := meta.Verify(data); err != nil {
+				slog.Error("Downloaded vendordep is corrupt", "file", d.FileName, "error", err)
+				return nil, nil, "", err
+			}
+		}
+	}
+	slog.Debug("Downloaded vendordep", "file", d.FileName, "url", url)
+
+	dep, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return data, dep, url, nil
+}
+
+// ParseQuery turn something the user typed in to find a vendordep into a
+// Vendordep which can be passed to Matches. Urls are treated as a jsonUrl,
+// uuids as a uuid and everything else as a name with an optional version.
+func ParseQuery(query string) Vendordep {
+	if strings.HasPrefix(query, "http") {
+		return Vendordep{ JsonUrl: query }
+	}
+	if uuidRe.MatchString(query) {
+		return Vendordep{ UUID: query }
+	}
+	if matches := fileNameRe.FindStringSubmatch(query); len(matches) > 2 {
+		return Vendordep{ Name: matches[1], Version: matches[2] }
+	}
+
+	return Vendordep{ Name: query }
+}
+
+// IsNetworkError check if an error was caused by not being able to reach the
+// server at all, as opposed to the server telling us something went wrong.
+func IsNetworkError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || artifactory.IsUnavailable(err)
+}
+
+// FetchVendorDep download and parse the vendordep found at url. The raw json is
+// returned alongside the parsed vendordep so that it can be written to disk
+// exactly as the vendor published it.
+func FetchVendorDep(url string) ([]byte, *Vendordep, error) {
+	data, dep, _, err := fetchVendorDep(url)
+	return data, dep, err
+}
+
+// fetchVendorDep the same as FetchVendorDep but the url the vendordep actually
+// came from after following any redirects is returned as well
+func fetchVendorDep(url string) ([]byte, *Vendordep, string, error) {
+	// vendordeps are small, a server which takes longer than Timeout to send
+	// one isn't going to
+	client := http.Client{ Timeout: Timeout }
+	resp, err := client.Get(url)
+	if err != nil {
+		slog.Error("Failed to download vendordep", "url", url, "error", err)
+		return nil, nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("Failed to download vendordep", "url", url, "status", resp.Status)
+		return nil, nil, "", errors.New("unexpected status: " + resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		slog.Error("Failed to read vendordep", "url", url, "error", err)
+		return nil, nil, "", err
+	}
+
+	dep, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return data, dep, resp.Request.URL.String(), nil
+}
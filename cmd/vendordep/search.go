@@ -0,0 +1,208 @@
+package vendordep
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"rph/cmd/vendordep/artifactory"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// searchUnavailable set once the artifactory has refused an AQL query, most
+// don't allow them so there's no point asking again for the rest of the run
+var searchUnavailable atomic.Bool
+
+// searchMarketplace find the files on the marketplace for year matching the
+// pattern with a single AQL query
+func searchMarketplace(year string, pattern string) ([]artifactory.SearchResult, error) {
+	if searchUnavailable.Load() {
+		return nil, artifactory.ErrSearchUnavailable
+	}
+
+	repo, dir, _ := strings.Cut(strings.TrimSuffix(marketplacePath, "/"), "/")
+
+	query := fmt.Sprintf(`items.find({"repo":%s,"path":%s,"name":{"$match":%s}}).include("repo","path","name","modified")`,
+		artifactory.AQLString(repo), artifactory.AQLString(dir + "/" + year), artifactory.AQLString(pattern))
+
+	results, err := marketplace().AQL(context.Background(), query)
+	if errors.Is(err, artifactory.ErrSearchUnavailable) {
+		searchUnavailable.Store(true)
+	}
+	return results, err
+}
+
+// searchOnlineDep find every version of a vendordep on the marketplace for year
+// with a single AQL query, rather than listing everything for the year
+func searchOnlineDep(year string, name string) ([]OnlineVendordep, error) {
+	results, err := searchMarketplace(year, name + "-*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []OnlineVendordep
+	for _, r := range results {
+		matches := fileNameRe.FindStringSubmatch(r.Name())
+		if len(matches) < 3 || !strings.EqualFold(matches[1], name) { continue }
+
+		deps = append(deps, OnlineVendordep{
+			VendordepName: matches[1],
+			Version: matches[2],
+			FileName: r.Name(),
+			Year: year,
+			LastModTime: r.Modified,
+		})
+	}
+
+	SortVersions(deps)
+	return deps, nil
+}
+
+// how many vendordeps StatAll asks the artifactory about at once
+const statWorkers = 8
+
+// StatAll fill in LastModTime for every vendordep which doesn't have it yet. The
+// marketplace is searched once for each year, anything the search can't answer
+// is stat'd a few at a time.
+func StatAll(deps []*OnlineVendordep) {
+	modified := map[string]time.Time{}
+	searched := map[string]bool{}
+	for _, d := range deps {
+		if !d.LastModTime.IsZero() || searched[d.Year] { continue }
+		searched[d.Year] = true
+
+		results, err := searchMarketplace(d.Year, "*.json")
+		if err != nil {
+			if !errors.Is(err, artifactory.ErrSearchUnavailable) {
+				slog.Debug("Searching the marketplace failed, stating vendordeps instead", "year", d.Year, "error", err)
+			}
+			continue
+		}
+		for _, r := range results {
+			modified[d.Year + "/" + r.Name()] = r.Modified
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, statWorkers)
+	for _, d := range deps {
+		if !d.LastModTime.IsZero() { continue }
+		if t := modified[d.Year + "/" + d.FileName]; !t.IsZero() {
+			d.LastModTime = t
+			continue
+		}
+
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := d.Stat(); err != nil {
+				slog.Debug("Unable to stat vendordep", "file", d.FileName, "error", err)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// onlineVersions every version of a vendordep on the marketplace for year. A
+// search is tried first and when the artifactory won't search for us, or
+// doesn't find anything, the marketplace is listed instead.
+func onlineVersions(year string, name string) ([]OnlineVendordep, error) {
+	deps, err := searchOnlineDep(year, name)
+	if err == nil && len(deps) > 0 {
+		return deps, nil
+	}
+	if err != nil && !errors.Is(err, artifactory.ErrSearchUnavailable) {
+		slog.Debug("Searching the marketplace failed, listing it instead", "name", name, "error", err)
+	}
+
+	online, err := ListAvailableOnlineDeps(year)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, deps := range online {
+		if strings.EqualFold(k, name) {
+			return deps, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// MavenArtifact a version of a maven artifact which is on an artifactory
+type MavenArtifact struct {
+	GroupId string `json:"groupId"`
+	ArtifactId string `json:"artifactId"`
+	Version string `json:"version"`
+	Repo string `json:"repo"`
+}
+
+// SearchArtifacts find the artifacts on the artifactory matching coords, which
+// is "groupId[:artifactId[:version]]". Artifacts in any group under groupId are
+// found too.
+func SearchArtifacts(coords string) ([]MavenArtifact, error) {
+	parts := strings.Split(coords, ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return nil, errors.New("expected groupId[:artifactId[:version]] but got " + coords)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+
+	// artifactories group their artifacts under a longer groupId than the
+	// vendor's domain (com.revrobotics.frc) so the group is matched as a prefix
+	group := strings.TrimSuffix(parts[0], "*")
+	results, err := marketplace().SearchGAVC(context.Background(), group + "*", parts[1], parts[2], "")
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []MavenArtifact
+	for _, r := range results {
+		// repo/group/as/dirs/artifactId/version/file
+		dirs := strings.Split(r.Path, "/")
+		if len(dirs) < 5 { continue }
+
+		n := len(dirs)
+		groupId := strings.Join(dirs[1:n - 3], ".")
+		// the wildcard would also match com.revroboticsfoo
+		if groupId != group && !strings.HasPrefix(groupId, group + ".") { continue }
+
+		a := MavenArtifact{
+			GroupId: groupId,
+			ArtifactId: dirs[n - 3],
+			Version: dirs[n - 2],
+			Repo: r.Repo(),
+		}
+		if !slices.Contains(artifacts, a) {
+			artifacts = append(artifacts, a)
+		}
+	}
+
+	slices.SortFunc(artifacts, func(a, b MavenArtifact) int {
+		return cmp.Or(
+			strings.Compare(a.GroupId, b.GroupId),
+			strings.Compare(a.ArtifactId, b.ArtifactId),
+			compareVersions(b.Version, a.Version),
+			strings.Compare(a.Repo, b.Repo),
+		)
+	})
+	return artifacts, nil
+}
+
+// Uses check if the vendordep depends on a version of a maven artifact
+func (v *Vendordep) Uses(groupId string, artifactId string, version string) bool {
+	matches := func(g, a, ver string) bool {
+		return g == groupId && a == artifactId && ver == version
+	}
+
+	return slices.ContainsFunc(v.JavaDependencies, func(d JavaDepedency) bool { return matches(d.GroupId, d.ArtifactId, d.Version) }) ||
+		slices.ContainsFunc(v.JniDependencies, func(d JniDependency) bool { return matches(d.GroupId, d.ArtifactId, d.Version) }) ||
+		slices.ContainsFunc(v.CppDependencies, func(d CppDependency) bool { return matches(d.GroupId, d.ArtifactId, d.Version) })
+}
@@ -0,0 +1,115 @@
+package vendordep
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testMarketplace point the marketplace at a fake artifactory which refuses
+// every AQL query, lists the 2025 marketplace and answers gavc searches with
+// results
+func testMarketplace(t *testing.T, results []string) (aqlRequests func() int, gavcGroup func() string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	aql, group := 0, ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/search/aql":
+			aql++
+			http.Error(w, "anonymous searches are not allowed", http.StatusForbidden)
+		case "/api/search/gavc":
+			group = r.URL.Query().Get("g")
+			var body struct {
+				Results []map[string]string `json:"results"`
+			}
+			for _, p := range results {
+				body.Results = append(body.Results, map[string]string{ "uri": "http://" + r.Host + "/api/storage/" + p })
+			}
+			json.NewEncoder(w).Encode(body)
+		case "/api/storage/" + marketplacePath + "2025":
+			w.Write([]byte(`{"children":[{"uri":"/REVLib-2025.0.2.json"},{"uri":"/REVLib-2025.0.3.json"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	urls, cache, timeout := ArtifactoryUrls, ResponseCache, Timeout
+	t.Cleanup(func() {
+		ArtifactoryUrls, ResponseCache, Timeout = urls, cache, timeout
+		searchUnavailable.Store(false)
+	})
+	ArtifactoryUrls, ResponseCache, Timeout = []string{ server.URL }, nil, 5 * time.Second
+	searchUnavailable.Store(false)
+
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return aql
+	}, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return group
+	}
+}
+
+func TestOnlineVersionsRemembersSearchIsUnavailable(t *testing.T) {
+	aqlRequests, _ := testMarketplace(t, nil)
+
+	for range 3 {
+		deps, err := onlineVersions("2025", "revlib")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deps) != 2 || deps[0].Version != "2025.0.3" {
+			t.Fatalf("onlineVersions = %+v", deps)
+		}
+	}
+
+	if n := aqlRequests(); n != 1 {
+		t.Errorf("AQL was tried %d times, it should only be tried once", n)
+	}
+}
+
+func TestSearchArtifactsMatchesGroupPrefix(t *testing.T) {
+	_, gavcGroup := testMarketplace(t, []string{
+		"maven/com/revrobotics/frc/REVLib-java/2025.0.2/REVLib-java-2025.0.2.jar",
+		"maven/com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.jar",
+		"maven/com/revrobotics/frc/REVLib-java/2025.0.3/REVLib-java-2025.0.3.pom",
+		"maven/com/revroboticsfoo/thing/1.0/thing-1.0.jar",
+	})
+
+	artifacts, err := SearchArtifacts("com.revrobotics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := gavcGroup(); g != "com.revrobotics*" {
+		t.Errorf("searched for the group %q, want com.revrobotics*", g)
+	}
+
+	want := []MavenArtifact{
+		{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-java", Version: "2025.0.3", Repo: "maven" },
+		{ GroupId: "com.revrobotics.frc", ArtifactId: "REVLib-java", Version: "2025.0.2", Repo: "maven" },
+	}
+	if len(artifacts) != len(want) {
+		t.Fatalf("SearchArtifacts = %+v, want %+v", artifacts, want)
+	}
+	for i := range want {
+		if artifacts[i] != want[i] {
+			t.Errorf("SearchArtifacts()[%d] = %+v, want %+v", i, artifacts[i], want[i])
+		}
+	}
+
+	if _, err := SearchArtifacts("a:b:c:d"); err == nil {
+		t.Error("SearchArtifacts accepted too many coordinates")
+	}
+}
@@ -1,80 +1,163 @@
 package vendordep
 
 import (
+	"bytes"
+	"errors"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"rph/state"
+	"strings"
 )
 
 const vendordepDir = "vendordeps"
 
+// vendordeps which rph has edited are cached separately so they're never
+// mistaken for the vendor's original file
+const editedDir = "edited"
+
+// directories in the vendordep cache which don't hold the vendor's vendordeps
+var privateDirs = map[string]bool{ trashDir: true, editedDir: true }
+
 func MkCacheDir() {
 	os.MkdirAll(filepath.Join(state.CachePath, vendordepDir), 0755);
 }
 
-func Trash(path string) error {
-	_, err := os.Stat(path)
-	if err != nil {
-		slog.Error("Can't trash file, path must be a valid file", "path", path, "error", err)
-		return err
+// unsafeCacheRe anything which shouldn't be in a file name in the cache
+var unsafeCacheRe = regexp.MustCompile(`[^A-Za-z0-9._+-]`)
+
+// cacheName make a value from a vendordep safe to use in a file name in the
+// cache, vendordeps come from anyone and mustn't be able to write anywhere else
+func cacheName(s string) string {
+	return unsafeCacheRe.ReplaceAllString(s, "_")
+}
+
+// Cache keep a copy of a vendordep so it can be installed again without
+// downloading it
+func Cache(data []byte, dep *Vendordep) error {
+	year := cacheName(string(dep.FrcYear))
+	if strings.Trim(year, ".") == "" || privateDirs[year] {
+		year = "unknown"
 	}
 
-	file, err := os.Open(path)
+	dir := filepath.Join(state.CachePath, vendordepDir, year)
+	err := os.MkdirAll(dir, 0755)
 	if err != nil {
-		slog.Error("Failed to open vendordep file", "error", err)
+		slog.Error("Failed to make vendordep cache directory", "error", err)
 		return err
 	}
-	defer file.Close()
 
-	dep, err := Parse(file)
-	if err != nil {
-		slog.Error("Failed to parse vendordep file", "error", err)
-	}
+	return os.WriteFile(filepath.Join(dir, cacheName(dep.Name + "-" + dep.Version) + ".json"), data, 0644)
+}
 
-	err = os.Rename(path, filepath.Join(state.CachePath, vendordepDir, dep.FileName))
+// cacheEdited keep a copy of a vendordep which has been edited, so that it can
+// be restored by its checksum
+func cacheEdited(data []byte) error {
+	dir := filepath.Join(state.CachePath, vendordepDir, editedDir)
+	err := os.MkdirAll(dir, 0755)
 	if err != nil {
-		slog.Info("Failed to move vendor dep", "error", err)
+		slog.Error("Failed to make vendordep cache directory", "error", err)
 		return err
 	}
 
-	return nil
+	return os.WriteFile(filepath.Join(dir, Checksum(data) + ".json"), data, 0644)
 }
 
-// hasVendorDepOnDisk check if a vendor dep is already on your disk, this is only
-// useful if you've got the uuid of the vendordep you would like to install or
-// are in a very percarious situation where you have no internet and any version
-// of your vendordep will do.
-func hasVendorDepOnDisk(dep Vendordep, strict bool) (bool, error) {
-	path := filepath.Join(state.CachePath, vendordepDir)
+// findCachedChecksum find a vendordep file in the cache who's contents match the
+// checksum
+func findCachedChecksum(sum string) ([]byte, error) {
+	var found []byte
 
-	// by default we're not matching
-	matches := false
+	err := filepath.WalkDir(filepath.Join(state.CachePath, vendordepDir), func(path string, d os.DirEntry, err error) error {
+		if err != nil { return err }
+		if d.IsDir() || !strings.HasSuffix(path, ".json") { return nil }
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
 
-	err := filepath.WalkDir(path, func(path string, d os.DirEntry, err error) error {
+		if Checksum(data) == sum {
+			found = data
+			return filepath.SkipAll
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		slog.Error("Failed to walk the vendordep directory", "error", err)
+		return nil, err
+	}
+	if found == nil {
+		return nil, os.ErrNotExist
+	}
+
+	return found, nil
+}
+
+// FindCachedVendorDep find a vendor dep which is already on your disk, this is
+// only useful if you've got the uuid of the vendordep you would like to install
+// or are in a very percarious situation where you have no internet and any
+// version of your vendordep will do. Only versions c allows are considered and
+// when multiple cached vendordeps match the newest one is returned.
+func FindCachedVendorDep(dep Vendordep, strict bool, c Constraint) ([]byte, *Vendordep, error) {
+	var bestData []byte
+	var best *Vendordep
+
+	root := filepath.Join(state.CachePath, vendordepDir)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil { return err }
-		if d.IsDir() { return nil }
+		if d.IsDir() && filepath.Dir(path) == root && privateDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") { return nil }
 
-		file, err := os.Open(filepath.Join(path, d.Name()))
+		data, err := os.ReadFile(path)
 		if err != nil {
 			slog.Error("Failed to open vendordep file", "error", err)
 			return err
 		}
-		defer file.Close()
 
-		new_dep, err := Parse(file)
+		newDep, err := Parse(bytes.NewReader(data))
 		if err != nil {
-			slog.Error("Failed to parse vendordep file", "error", err)
+			slog.Warn("Skipping cached vendordep which can't be parsed", "path", path)
+			return nil
 		}
 
-		matches = new_dep.Matches(dep, strict)
+		if !newDep.Matches(dep, strict) || !c.Allows(newDep.Version) { return nil }
+
+		if best == nil || compareVersions(best.Version, newDep.Version) < 0 {
+			best = newDep
+			bestData = data
+		}
 		return nil
 	})
 
 	if err != nil {
 		slog.Error("Failed to walk the vendordep directory", "error", err)
-		return false, err
+		return nil, nil, err
+	}
+	if best == nil {
+		return nil, nil, errors.New("Vendordep not found in the cache")
 	}
 
-	return matches, nil
+	return bestData, best, nil
+}
+
+// CachePath the directory cached vendordeps are kept in
+func CachePath() string {
+	return filepath.Join(state.CachePath, vendordepDir)
+}
+
+// MavenCachePath the local maven repository vendordep artifacts are downloaded
+// into
+func MavenCachePath() string {
+	return filepath.Join(state.CachePath, "maven")
+}
+
+// HttpCachePath where responses from the artifactory are cached
+func HttpCachePath() string {
+	return filepath.Join(state.CachePath, "http")
 }
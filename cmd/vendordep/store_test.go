@@ -0,0 +1,102 @@
+package vendordep
+
+import (
+	"os"
+	"path/filepath"
+	"rph/state"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCacheStaysInTheCache(t *testing.T) {
+	state.CachePath = t.TempDir()
+	root := filepath.Join(state.CachePath, vendordepDir)
+
+	tests := []Vendordep{
+		{ Name: "../../../escaped", Version: "1.0.0", FrcYear: "2025" },
+		{ Name: "A", Version: "../../escaped", FrcYear: "2025" },
+		{ Name: `..\\escaped`, Version: "1.0.0", FrcYear: "2025" },
+		{ Name: "A", Version: "1.0.0", FrcYear: ".." },
+		{ Name: "A", Version: "1.0.0", FrcYear: "../../escaped" },
+		{ Name: "A", Version: "1.0.0", FrcYear: trashDir },
+	}
+
+	for _, dep := range tests {
+		if err := Cache([]byte("{}"), &dep); err != nil {
+			t.Fatalf("Cache(%+v) = %v", dep, err)
+		}
+	}
+
+	err := filepath.WalkDir(state.CachePath, func(path string, d os.DirEntry, err error) error {
+		if err != nil { return err }
+		if d.IsDir() { return nil }
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".." + string(filepath.Separator)) {
+			t.Errorf("%s was written outside of the vendordep cache", path)
+		}
+		if dir := filepath.Dir(rel); dir == trashDir || dir == "." {
+			t.Errorf("%s was written into %q", rel, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindCachedVendorDep(t *testing.T) {
+	oldCachePath := state.CachePath
+	state.CachePath = t.TempDir()
+	t.Cleanup(func() { state.CachePath = oldCachePath })
+
+	for _, version := range []string{ "25.0.4", "25.1.0", "25.1.3", "25.2.0" } {
+		dep := Vendordep{ Name: "Phoenix6", Version: version, FrcYear: "2025" }
+		if err := Cache(vendordepData(dep.Name, version, testUUID), &dep); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query Vendordep
+		strict bool
+		constraint string
+		// empty when nothing should be found
+		want string
+	}{
+		{ Vendordep{ Name: "phoenix6" }, false, "", "25.2.0" },
+		{ Vendordep{ UUID: testUUID }, true, "", "25.2.0" },
+		// the newest version the constraint allows rather than the newest
+		{ Vendordep{ Name: "Phoenix6" }, false, "~25.1", "25.1.3" },
+		{ Vendordep{ Name: "Phoenix6" }, false, "<25.1", "25.0.4" },
+		{ Vendordep{ UUID: testUUID }, true, "^25.1.1", "25.2.0" },
+		{ Vendordep{ Name: "Phoenix6" }, false, "~24.3", "" },
+		{ Vendordep{ Name: "Phoenix6", Version: "25.1.0" }, true, "", "25.1.0" },
+		{ Vendordep{ Name: "Phoenix6", Version: "25.1.0" }, true, ">25.1.0", "" },
+		// a strict search needs a version
+		{ Vendordep{ Name: "Phoenix6" }, true, "", "" },
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, dep, err := FindCachedVendorDep(tt.query, tt.strict, c)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("FindCachedVendorDep(%+v, %v, %s) = %s, want nothing", tt.query, tt.strict, c, dep.Version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindCachedVendorDep(%+v, %v, %s) = %v", tt.query, tt.strict, c, err)
+			continue
+		}
+		if dep.Version != tt.want || !slices.Equal(data, vendordepData("Phoenix6", tt.want, testUUID)) {
+			t.Errorf("FindCachedVendorDep(%+v, %v, %s) = %s, want %s", tt.query, tt.strict, c, dep.Version, tt.want)
+		}
+	}
+}
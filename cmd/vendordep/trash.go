@@ -0,0 +1,270 @@
+package vendordep
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"rph/state"
+	"slices"
+	"strings"
+	"time"
+)
+
+const trashDir = "trash"
+
+// TrashEntry a vendordep which has been removed from a project, everything
+// needed to put it back is recorded alongside the file.
+type TrashEntry struct {
+	ID string `json:"-"`
+	Project string `json:"project"`
+	FileName string `json:"fileName"`
+	Name string `json:"name"`
+	Version string `json:"version"`
+	Source string `json:"source,omitempty"`
+	TrashedAt time.Time `json:"trashedAt"`
+}
+
+func trashPath() string {
+	return filepath.Join(state.CachePath, vendordepDir, trashDir)
+}
+
+// Path the location of the trashed vendordep file
+func (e TrashEntry) Path() string {
+	return filepath.Join(trashPath(), e.ID + ".json")
+}
+
+func (e TrashEntry) metaPath() string {
+	return filepath.Join(trashPath(), e.ID + ".meta")
+}
+
+// moveFile rename a file falling back to copying it when the trash is on a
+// different filesystem than the project
+func moveFile(from string, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(from)
+	if err != nil { return err }
+	defer in.Close()
+
+	out, err := os.Create(to)
+	if err != nil { return err }
+
+	_, err = io.Copy(out, in)
+	// a failed close can mean the copy never made it to disk
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(to)
+		return err
+	}
+
+	in.Close()
+	return os.Remove(from)
+}
+
+// Trash move a vendordep out of a project and into the trash so it can be
+// restored later
+func Trash(path string) error {
+	_, err := trash(path, true)
+	return err
+}
+
+// TrashCopy put a copy of a vendordep into the trash leaving the original in
+// the project, used before a vendordep is overwritten so it can be restored
+func TrashCopy(path string) (*TrashEntry, error) {
+	return trash(path, false)
+}
+
+func trash(path string, move bool) (*TrashEntry, error) {
+	_, err := os.Stat(path)
+	if err != nil {
+		slog.Error("Can't trash file, path must be a valid file", "path", path, "error", err)
+		return nil, err
+	}
+
+	// the file is read up front so it isn't held open while it's being moved,
+	// windows won't move an open file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Error("Failed to read vendordep file", "error", err)
+		return nil, err
+	}
+
+	dep, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		slog.Error("Failed to parse vendordep file", "error", err)
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := TrashEntry{
+		ID: now.UTC().Format("20060102T150405.000000000") + "-" + strings.TrimSuffix(filepath.Base(path), ".json"),
+		Project: filepath.Dir(filepath.Dir(abs)),
+		FileName: filepath.Base(path),
+		Name: dep.Name,
+		Version: dep.Version,
+		TrashedAt: now,
+	}
+
+	if lock, err := LoadLock(entry.Project); err == nil {
+		if e := lock.Find(entry.FileName); e != nil {
+			entry.Source = e.Source
+		}
+	}
+
+	err = os.MkdirAll(trashPath(), 0755)
+	if err != nil {
+		slog.Error("Failed to make trash directory", "error", err)
+		return nil, err
+	}
+
+	meta, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(entry.metaPath(), meta, 0644)
+	if err != nil {
+		slog.Error("Failed to write trash entry", "error", err)
+		return nil, err
+	}
+
+	if move {
+		err = moveFile(path, entry.Path())
+	} else {
+		err = os.WriteFile(entry.Path(), data, 0644)
+	}
+	if err != nil {
+		slog.Error("Failed to move vendor dep", "error", err)
+		os.Remove(entry.metaPath())
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// ListTrash list every trashed vendordep, newest first
+func ListTrash() ([]TrashEntry, error) {
+	dirEntries, err := os.ReadDir(trashPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		slog.Error("Failed to read trash directory", "error", err)
+		return nil, err
+	}
+
+	var entries []TrashEntry
+	for _, d := range dirEntries {
+		id, ok := strings.CutSuffix(d.Name(), ".meta")
+		if !ok { continue }
+
+		data, err := os.ReadFile(filepath.Join(trashPath(), d.Name()))
+		if err != nil {
+			slog.Warn("Failed to read trash entry", "id", id, "error", err)
+			continue
+		}
+
+		var entry TrashEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			slog.Warn("Failed to decode trash entry", "id", id, "error", err)
+			continue
+		}
+		entry.ID = id
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b TrashEntry) int {
+		return b.TrashedAt.Compare(a.TrashedAt)
+	})
+
+	return entries, nil
+}
+
+// FindTrash find the most recently trashed vendordep with the given name or id
+func FindTrash(nameOrId string) (*TrashEntry, error) {
+	entries, err := ListTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.ID == nameOrId || strings.EqualFold(e.Name, nameOrId) {
+			return &e, nil
+		}
+	}
+
+	return nil, errors.New("Vendordep not found in the trash")
+}
+
+// Restore put a trashed vendordep back into a project under its original file
+// name, the entry is removed from the trash once it's been restored. A file
+// which is already in the project is only replaced when force is set.
+func Restore(projectDir string, entry TrashEntry, force bool) error {
+	if !validFileName(entry.FileName) {
+		return errors.New("invalid fileName in trash entry: " + entry.FileName)
+	}
+
+	path := filepath.Join(projectDir, vendordepDir, entry.FileName)
+	if _, err := os.Stat(path); err == nil && !force {
+		slog.Error("A vendordep with the same file name is already installed", "file", entry.FileName)
+		return errors.New(entry.FileName + " already exists, use --force to replace it")
+	}
+
+	data, err := os.ReadFile(entry.Path())
+	if err != nil {
+		slog.Error("Failed to read trashed vendordep", "id", entry.ID, "error", err)
+		return err
+	}
+
+	_, err = installFile(projectDir, entry.FileName, data, entry.Source, "")
+	if err != nil {
+		return err
+	}
+
+	return entry.Delete()
+}
+
+// Delete permanently remove an entry from the trash
+func (e TrashEntry) Delete() error {
+	err := os.Remove(e.Path())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.Remove(e.metaPath())
+}
+
+// PurgeTrash permanently remove every trashed vendordep older than age, an age
+// of zero removes everything. The number of removed entries is returned.
+func PurgeTrash(age time.Duration) (int, error) {
+	entries, err := ListTrash()
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, e := range entries {
+		if age > 0 && time.Since(e.TrashedAt) < age { continue }
+
+		if err := e.Delete(); err != nil {
+			slog.Error("Failed to purge trash entry", "id", e.ID, "error", err)
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
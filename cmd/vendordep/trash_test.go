@@ -0,0 +1,147 @@
+package vendordep
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	dir := testProject(t)
+	data := writeVendordep(t, dir, "A", "1.0.0", testUUID)
+	if _, err := Install(dir, data, "https://example.com/A.json", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, vendordepDir, "A.json")
+	if err := Trash(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("trashed vendordep is still in the project: %v", err)
+	}
+
+	entries, err := ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("ListTrash = %+v, want the trashed vendordep", entries)
+	}
+	e := entries[0]
+	if e.Name != "A" || e.Version != "1.0.0" || e.FileName != "A.json" || e.Source != "https://example.com/A.json" {
+		t.Errorf("trash entry = %+v", e)
+	}
+
+	for _, query := range []string{ "a", e.ID } {
+		found, err := FindTrash(query)
+		if err != nil || found.ID != e.ID {
+			t.Errorf("FindTrash(%s) = %+v, %v", query, found, err)
+		}
+	}
+	if _, err := FindTrash("B"); err == nil {
+		t.Error("FindTrash found something which was never trashed")
+	}
+
+	// a vendordep with the same file name is only replaced with force
+	replacement := writeVendordep(t, dir, "A", "2.0.0", testUUID)
+	if err := Restore(dir, e, false); err == nil {
+		t.Error("Restore replaced an installed vendordep without force")
+	}
+	if got, _ := os.ReadFile(path); string(got) != string(replacement) {
+		t.Errorf("Restore without force changed the installed vendordep:\n%s", got)
+	}
+
+	if err := Restore(dir, e, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != string(data) {
+		t.Errorf("Restore didn't put the trashed vendordep back:\n%s", got)
+	}
+	if entries, _ := ListTrash(); len(entries) != 0 {
+		t.Errorf("restored vendordep is still in the trash: %+v", entries)
+	}
+
+	lock, err := LoadLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := lock.Find("A.json"); l == nil || l.Sha256 != Checksum(data) || l.Source != e.Source {
+		t.Errorf("restored vendordep wasn't locked: %+v", l)
+	}
+}
+
+func TestRestoreRejectsInvalidFileNames(t *testing.T) {
+	dir := testProject(t)
+
+	e := TrashEntry{ ID: "x", FileName: "../../A.json" }
+	if err := Restore(dir, e, true); err == nil {
+		t.Error("Restore accepted a file name outside of the vendordeps directory")
+	}
+}
+
+// trashAt put a vendordep in the trash which was trashed at a specific time
+func trashAt(t *testing.T, dir string, name string, at time.Time) {
+	t.Helper()
+
+	writeVendordep(t, dir, name, "1.0.0", "")
+	if err := Trash(filepath.Join(dir, vendordepDir, name + ".json")); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := FindTrash(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.TrashedAt = at
+	meta, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(e.metaPath(), meta, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	dir := testProject(t)
+	now := time.Now()
+	trashAt(t, dir, "Old", now.Add(-60 * 24 * time.Hour))
+	trashAt(t, dir, "Recent", now.Add(-2 * 24 * time.Hour))
+	trashAt(t, dir, "New", now)
+
+	purged, err := PurgeTrash(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("PurgeTrash(30d) removed %d entries, want 1", purged)
+	}
+
+	entries, err := ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "New" || entries[1].Name != "Recent" {
+		t.Errorf("trash after purging old entries = %+v", entries)
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(e.Path()); err != nil {
+			t.Errorf("%s was purged: %v", e.Name, err)
+		}
+	}
+
+	purged, err = PurgeTrash(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Errorf("PurgeTrash(0) removed %d entries, want everything", purged)
+	}
+	if entries, _ := ListTrash(); len(entries) != 0 {
+		t.Errorf("trash after purging everything = %+v", entries)
+	}
+}
@@ -0,0 +1,193 @@
+package vendordep
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	SourceMarketplace = "marketplace"
+	SourceJsonUrl = "jsonUrl"
+)
+
+// how many times we'll follow a vendordep to a new jsonUrl before giving up,
+// this stops two vendordeps which point at each other from looping forever
+const maxJsonUrlHops = 5
+
+// Update describes the newest known version of an installed vendordep
+type Update struct {
+	Installed Vendordep
+	Latest *Vendordep
+	// Data is the raw json of Latest
+	Data []byte
+	Url string
+	Source string
+	LastModTime time.Time
+	// Manual set when the vendordep has no jsonUrl and the marketplace was
+	// checked and doesn't have it, there's no way for us to update it
+	Manual bool
+	// Err why the vendordep couldn't be checked for updates, e.g. the
+	// marketplace or its jsonUrl couldn't be reached
+	Err error
+}
+
+// Outdated check if the update is actually newer than what's installed
+func (u Update) Outdated() bool {
+	return u.Latest != nil && compareVersions(u.Installed.Version, u.Latest.Version) < 0
+}
+
+// onlineNameMatches check if the name used on the marketplace refers to the
+// installed vendordep, this is only a guess and the uuid should always be
+// checked once the vendordep has been downloaded.
+func onlineNameMatches(dep Vendordep, name string) bool {
+	return strings.EqualFold(dep.Name, name) ||
+		strings.EqualFold(strings.TrimSuffix(dep.FileName, ".json"), name)
+}
+
+// newestOnlineDep find the newest version of dep on the marketplace which c
+// allows, pre-releases are only considered if a pre-release is already installed
+// or c names one
+func newestOnlineDep(dep Vendordep, online map[string][]OnlineVendordep, c Constraint) *OnlineVendordep {
+	var candidates []OnlineVendordep
+
+	for name, versions := range online {
+		if !onlineNameMatches(dep, name) { continue }
+
+		for _, v := range versions {
+			if !c.Allows(v.Version) { continue }
+			if c.Any() && isPreRelease(v.Version) && !isPreRelease(dep.Version) { continue }
+			candidates = append(candidates, v)
+		}
+	}
+
+	SortVersions(candidates)
+	if len(candidates) == 0 {
+		return nil
+	}
+	return &candidates[0]
+}
+
+// ResolveJsonUrl download the vendordep at url, vendors sometimes move their
+// vendordeps between releases so when the downloaded vendordep has a different
+// jsonUrl that's followed as well. The newest vendordep found along the way
+// which c allows is returned along with the url it came from after any
+// redirects, when c doesn't allow any of them the newest one is returned. A
+// vendordep with a different uuid is a different library, a moved or taken over
+// jsonUrl isn't allowed to replace the installed one with it.
+func ResolveJsonUrl(url string, uuid string, c Constraint) ([]byte, *Vendordep, string, error) {
+	var bestData []byte
+	var best *Vendordep
+	var bestUrl string
+
+	visited := map[string]bool{}
+	for range maxJsonUrlHops {
+		data, dep, finalUrl, err := fetchVendorDep(url)
+		if err != nil {
+			// we've already got something, a dead link further along
+			// shouldn't throw that away
+			if best != nil {
+				slog.Warn("Unable to follow vendordep to its new jsonUrl", "url", url, "error", err)
+				break
+			}
+			return nil, nil, "", err
+		}
+		if dep.UUID != uuid {
+			// whatever we've already got is still the right vendordep
+			if best != nil {
+				slog.Warn("Vendordep moved to a jsonUrl with a different uuid, ignoring it", "url", finalUrl, "uuid", dep.UUID)
+				break
+			}
+			slog.Error("Vendordep at jsonUrl has a different uuid", "url", finalUrl, "uuid", dep.UUID, "want", uuid)
+			return nil, nil, "", fmt.Errorf("%s is a different vendordep, it has the uuid %s", finalUrl, dep.UUID)
+		}
+		visited[url] = true
+		visited[finalUrl] = true
+
+		better := best == nil || compareVersions(best.Version, dep.Version) < 0
+		if best != nil && c.Allows(best.Version) != c.Allows(dep.Version) {
+			better = c.Allows(dep.Version)
+		}
+		if better {
+			bestData, best, bestUrl = data, dep, finalUrl
+		}
+
+		if dep.JsonUrl == "" || visited[dep.JsonUrl] {
+			return bestData, best, bestUrl, nil
+		}
+
+		slog.Debug("Vendordep has moved to a new jsonUrl", "name", dep.Name, "from", url, "to", dep.JsonUrl)
+		url = dep.JsonUrl
+	}
+
+	return bestData, best, bestUrl, nil
+}
+
+// FindUpdates look for the newest version of each vendordep in deps, both the
+// marketplace for year and the vendordep's own jsonUrl are checked. Versions
+// outside of a dep's constraint, keyed by file name, are never suggested. An
+// Update is returned for every dep even when there's nothing newer available.
+func FindUpdates(deps []Vendordep, year string, constraints map[string]Constraint) []Update {
+	online, onlineErr := ListAvailableOnlineDeps(year)
+	if onlineErr != nil {
+		slog.Warn("Unable to check the marketplace for updates", "year", year, "error", onlineErr)
+	}
+
+	updates := make([]Update, len(deps))
+	// the marketplace updates, they're stat'd all together at the end
+	newest := map[int]*OnlineVendordep{}
+	for i, dep := range deps {
+		update := Update{ Installed: dep, Err: onlineErr }
+		c := constraints[dep.FileName]
+
+		if n := newestOnlineDep(dep, online, c); n != nil {
+			data, latest, url, err := n.Fetch()
+			if err != nil {
+				slog.Warn("Unable to fetch vendordep from the marketplace", "name", dep.Name, "error", err)
+				update.Err = err
+			} else if latest.UUID != dep.UUID {
+				slog.Debug("Marketplace vendordep has a different uuid, ignoring it", "name", dep.Name, "file", n.FileName)
+			} else {
+				update.Latest = latest
+				update.Data = data
+				update.Url = url
+				update.Source = SourceMarketplace
+				newest[i] = n
+			}
+		}
+
+		if dep.JsonUrl != "" {
+			data, latest, url, err := ResolveJsonUrl(dep.JsonUrl, dep.UUID, c)
+			if err != nil {
+				slog.Warn("Unable to fetch vendordep from its jsonUrl", "name", dep.Name, "url", dep.JsonUrl, "error", err)
+				update.Err = err
+			} else if !c.Allows(latest.Version) {
+				slog.Debug("Vendordep from jsonUrl is outside of its constraint", "name", dep.Name, "version", latest.Version, "constraint", c)
+			} else if update.Latest == nil || compareVersions(update.Latest.Version, latest.Version) < 0 {
+				update.Latest = latest
+				update.Data = data
+				update.Url = url
+				update.Source = SourceJsonUrl
+				delete(newest, i)
+			}
+		} else if update.Latest == nil && update.Err == nil {
+			// only when we know for sure the marketplace doesn't have it, not
+			// because it couldn't be reached
+			update.Manual = true
+		}
+
+		updates[i] = update
+	}
+
+	stat := make([]*OnlineVendordep, 0, len(newest))
+	for _, n := range newest {
+		stat = append(stat, n)
+	}
+	StatAll(stat)
+	for i, n := range newest {
+		updates[i].LastModTime = n.LastModTime
+	}
+
+	return updates
+}
@@ -0,0 +1,91 @@
+package vendordep
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	testUUID = "00000000-0000-0000-0000-000000000001"
+	otherUUID = "00000000-0000-0000-0000-000000000002"
+)
+
+// jsonUrlServer serve vendordeps by path, deps can be filled in once the
+// server's url is known
+func jsonUrlServer(t *testing.T, deps map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dep, ok := deps[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, dep)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func jsonUrlDep(version string, uuid string, jsonUrl string) string {
+	return fmt.Sprintf(`{"fileName":"A.json","name":"A","version":%q,"uuid":%q,"jsonUrl":%q}`, version, uuid, jsonUrl)
+}
+
+func TestResolveJsonUrlFollowsMoves(t *testing.T) {
+	deps := map[string]string{}
+	server := jsonUrlServer(t, deps)
+	deps["/old.json"] = jsonUrlDep("1.0.0", testUUID, server.URL + "/new.json")
+	deps["/new.json"] = jsonUrlDep("2.0.0", testUUID, server.URL + "/new.json")
+
+	_, dep, url, err := ResolveJsonUrl(server.URL + "/old.json", testUUID, Constraint{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep.Version != "2.0.0" || url != server.URL + "/new.json" {
+		t.Errorf("ResolveJsonUrl = %s from %s, want 2.0.0 from the new jsonUrl", dep.Version, url)
+	}
+}
+
+func TestResolveJsonUrlRejectsOtherUUIDs(t *testing.T) {
+	deps := map[string]string{}
+	server := jsonUrlServer(t, deps)
+	deps["/taken.json"] = jsonUrlDep("9.0.0", otherUUID, "")
+	deps["/moved.json"] = jsonUrlDep("1.0.0", testUUID, server.URL + "/taken.json")
+
+	if _, dep, _, err := ResolveJsonUrl(server.URL + "/taken.json", testUUID, Constraint{}); err == nil {
+		t.Errorf("ResolveJsonUrl accepted a vendordep with a different uuid: %+v", dep)
+	}
+
+	// the vendordep found before the jsonUrl was taken over is kept
+	_, dep, _, err := ResolveJsonUrl(server.URL + "/moved.json", testUUID, Constraint{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep.Version != "1.0.0" {
+		t.Errorf("ResolveJsonUrl = %s, want 1.0.0 from before the uuid changed", dep.Version)
+	}
+}
+
+func TestFetchVendorDepTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+
+	timeout := Timeout
+	t.Cleanup(func() { Timeout = timeout })
+	Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if _, _, err := FetchVendorDep(server.URL + "/A.json"); err == nil {
+		t.Fatal("FetchVendorDep of a server which never answers succeeded")
+	}
+	if waited := time.Since(start); waited > 5 * time.Second {
+		t.Errorf("FetchVendorDep waited %s, want around Timeout", waited)
+	}
+}
@@ -0,0 +1,364 @@
+package vendordep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"rph/utils"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// KnownPlatforms every platform wpilib builds native libraries for
+var KnownPlatforms = []string{
+	"linuxathena",
+	"linuxarm32",
+	"linuxarm64",
+	"linuxx86-64",
+	"osxuniversal",
+	"windowsx86-64",
+	"windowsx86",
+	"windowsarm64",
+}
+
+const (
+	SimModeHardware = "hwsim"
+	SimModeSoftware = "swsim"
+)
+
+// SimModes the values simMode may have, hwsim libraries are used when
+// simulating with hardware and swsim libraries when simulating in software
+var SimModes = []string{ SimModeHardware, SimModeSoftware }
+
+var yearRe = regexp.MustCompile(`^\d{4}$`)
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindBool
+	kindStringList
+	kindObjectList
+	kindObject
+	// a string or a number, like frcYear
+	kindYear
+	// anything at all, for fields the schema leaves up to the vendor
+	kindAny
+)
+
+func (k fieldKind) String() string {
+	switch k {
+	case kindString: return "a string"
+	case kindBool: return "a boolean"
+	case kindStringList: return "a list of strings"
+	case kindObjectList: return "a list of objects"
+	case kindObject: return "an object"
+	case kindYear: return "a year"
+	case kindAny: return "anything"
+	}
+	return "unknown"
+}
+
+type field struct {
+	kind fieldKind
+	required bool
+}
+
+// the wpilib vendordep schema
+var (
+	vendordepFields = map[string]field{
+		"fileName": { kindString, true },
+		"name": { kindString, true },
+		"version": { kindString, true },
+		"frcYear": { kindYear, true },
+		"uuid": { kindString, true },
+		"mavenUrls": { kindStringList, true },
+		"jsonUrl": { kindString, true },
+		"javaDependencies": { kindObjectList, true },
+		"jniDependencies": { kindObjectList, true },
+		"cppDependencies": { kindObjectList, true },
+		"requires": { kindObjectList, false },
+		"conflictsWith": { kindObjectList, false },
+		// free for vendors to use however they like
+		"extraValues": { kindAny, false },
+		// not part of the schema, this is where rph tags vendordeps
+		MetadataKey: { kindObject, false },
+	}
+	javaFields = map[string]field{
+		"groupId": { kindString, true },
+		"artifactId": { kindString, true },
+		"version": { kindString, true },
+	}
+	jniFields = map[string]field{
+		"groupId": { kindString, true },
+		"artifactId": { kindString, true },
+		"version": { kindString, true },
+		"isJar": { kindBool, true },
+		"skipInvalidPlatforms": { kindBool, true },
+		"validPlatforms": { kindStringList, true },
+		"simMode": { kindString, false },
+	}
+	cppFields = map[string]field{
+		"groupId": { kindString, true },
+		"artifactId": { kindString, true },
+		"version": { kindString, true },
+		"libName": { kindString, true },
+		"configuration": { kindString, false },
+		"headerClassifier": { kindString, true },
+		"sourcesClassifier": { kindString, false },
+		"sharedLibrary": { kindBool, true },
+		"skipInvalidPlatforms": { kindBool, true },
+		"binaryPlatforms": { kindStringList, true },
+		"simMode": { kindString, false },
+	}
+	requiresFields = map[string]field{
+		"uuid": { kindString, true },
+		"errorMessage": { kindString, true },
+		"offlineFileName": { kindString, true },
+		"onlineUrl": { kindString, false },
+	}
+	conflictsFields = map[string]field{
+		"uuid": { kindString, true },
+		"errorMessage": { kindString, true },
+		"offlineFileName": { kindString, true },
+	}
+)
+
+var listFields = map[string]map[string]field{
+	"javaDependencies": javaFields,
+	"jniDependencies": jniFields,
+	"cppDependencies": cppFields,
+	"requires": requiresFields,
+	"conflictsWith": conflictsFields,
+}
+
+type validator struct {
+	file string
+	problems []Problem
+}
+
+func (v *validator) problem(severity string, path string, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if path != "" {
+		msg = path + ": " + msg
+	}
+	v.problems = append(v.problems, Problem{ Severity: severity, File: v.file, Message: msg })
+}
+
+// hasKind check if a json value is of the given kind
+func hasKind(raw json.RawMessage, kind fieldKind) bool {
+	if kind == kindAny {
+		return true
+	}
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return false
+	}
+
+	var err error
+	switch kind {
+	case kindString:
+		var s string
+		err = json.Unmarshal(raw, &s)
+	case kindBool:
+		var b bool
+		err = json.Unmarshal(raw, &b)
+	case kindStringList:
+		var l []string
+		err = json.Unmarshal(raw, &l)
+	case kindObjectList:
+		var l []map[string]json.RawMessage
+		err = json.Unmarshal(raw, &l)
+	case kindObject:
+		var o map[string]json.RawMessage
+		err = json.Unmarshal(raw, &o)
+	case kindYear:
+		var y utils.StringOrNumber
+		err = json.Unmarshal(raw, &y)
+	}
+
+	return err == nil
+}
+
+// object check that raw is an object with only the given fields, each of the
+// right kind. The fields are returned so they can be checked further.
+func (v *validator) object(path string, raw json.RawMessage, fields map[string]field) map[string]json.RawMessage {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		if !json.Valid(raw) {
+			v.problem(SeverityError, path, "invalid json")
+		} else {
+			v.problem(SeverityError, path, "must be an object")
+		}
+		return nil
+	}
+
+	prefix := path
+	if prefix != "" {
+		prefix += "."
+	}
+
+	required := make([]string, 0, len(fields))
+	for k, f := range fields {
+		if f.required {
+			required = append(required, k)
+		}
+	}
+	sort.Strings(required)
+
+	for _, k := range required {
+		if _, ok := obj[k]; !ok {
+			v.problem(SeverityError, prefix + k, "missing required field")
+		}
+	}
+
+	// sorted so problems come out in the same order every time
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		f, ok := fields[k]
+		if !ok {
+			v.problem(SeverityError, prefix + k, "unknown field")
+			continue
+		}
+		if !hasKind(obj[k], f.kind) {
+			v.problem(SeverityError, prefix + k, "must be %s", f.kind)
+			delete(obj, k)
+		}
+	}
+
+	return obj
+}
+
+// Validate check a vendordep file against the wpilib vendordep schema. Unlike
+// Parse unknown fields, missing fields and values of the wrong type are all
+// reported, along with values which gradle won't accept.
+func Validate(file string, data []byte) []Problem {
+	v := &validator{ file: file }
+
+	root := v.object("", data, vendordepFields)
+	if root == nil {
+		return v.problems
+	}
+
+	listKeys := make([]string, 0, len(listFields))
+	for k := range listFields {
+		listKeys = append(listKeys, k)
+	}
+	sort.Strings(listKeys)
+
+	// the fields of each dependency which are present and of the right type
+	items := map[string][]map[string]json.RawMessage{}
+	for _, k := range listKeys {
+		raw, ok := root[k]
+		if !ok { continue }
+
+		var list []json.RawMessage
+		json.Unmarshal(raw, &list)
+		for i, item := range list {
+			items[k] = append(items[k], v.object(fmt.Sprintf("%s[%d]", k, i), item, listFields[k]))
+		}
+	}
+
+	// the structure is fine as far as we can tell, now look at the values.
+	// Fields of the wrong type were already reported so errors are ignored
+	// and we check what did decode.
+	var dep Vendordep
+	json.Unmarshal(data, &dep)
+
+	if _, ok := root["fileName"]; ok && !strings.HasSuffix(dep.FileName, ".json") {
+		v.problem(SeverityError, "fileName", "must end in .json")
+	}
+	if _, ok := root["fileName"]; ok && strings.ContainsAny(dep.FileName, `/\`) {
+		v.problem(SeverityError, "fileName", "must not be a path")
+	}
+	if _, ok := root["name"]; ok && strings.TrimSpace(dep.Name) == "" {
+		v.problem(SeverityError, "name", "must not be empty")
+	}
+	if _, ok := root["version"]; ok {
+		if _, err := ParseVersion(dep.Version); err != nil {
+			v.problem(SeverityWarning, "version", "%q isn't a version rph understands, it won't be sorted properly", dep.Version)
+		}
+	}
+	if _, ok := root["frcYear"]; ok && !yearRe.MatchString(string(dep.FrcYear)) {
+		v.problem(SeverityError, "frcYear", "%q is not a year", dep.FrcYear)
+	}
+	if _, ok := root["uuid"]; ok && !uuidRe.MatchString(dep.UUID) {
+		v.problem(SeverityError, "uuid", "%q is not a valid uuid", dep.UUID)
+	}
+	if _, ok := root["mavenUrls"]; ok {
+		if len(dep.MavenUrls) == 0 {
+			v.problem(SeverityError, "mavenUrls", "must not be empty")
+		}
+		for i, url := range dep.MavenUrls {
+			if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+				v.problem(SeverityError, fmt.Sprintf("mavenUrls[%d]", i), "%q is not a url", url)
+			}
+		}
+	}
+	if _, ok := root["jsonUrl"]; ok && dep.JsonUrl == "" {
+		v.problem(SeverityWarning, "jsonUrl", "is empty, the vendordep can't be updated automatically")
+	}
+
+	item := func(list string, i int) map[string]json.RawMessage {
+		if i < len(items[list]) {
+			return items[list][i]
+		}
+		return nil
+	}
+	artifact := func(path string, obj map[string]json.RawMessage, groupId, artifactId, version string) {
+		for _, f := range []struct{ name, value string }{
+			{ "groupId", groupId }, { "artifactId", artifactId }, { "version", version },
+		} {
+			if _, ok := obj[f.name]; ok && strings.TrimSpace(f.value) == "" {
+				v.problem(SeverityError, path + "." + f.name, "must not be empty")
+			}
+		}
+	}
+	platforms := func(path string, platforms []string) {
+		for i, p := range platforms {
+			if !slices.Contains(KnownPlatforms, p) {
+				v.problem(SeverityError, fmt.Sprintf("%s[%d]", path, i), "unknown platform %q, expected one of %s", p, strings.Join(KnownPlatforms, ", "))
+			}
+		}
+	}
+	simMode := func(path string, mode string) {
+		if mode != "" && !slices.Contains(SimModes, mode) {
+			v.problem(SeverityError, path, "unknown sim mode %q, expected one of %s", mode, strings.Join(SimModes, ", "))
+		}
+	}
+
+	for i, r := range dep.Requires {
+		if !uuidRe.MatchString(r.UUID) {
+			v.problem(SeverityError, fmt.Sprintf("requires[%d].uuid", i), "%q is not a valid uuid", r.UUID)
+		}
+	}
+	for i, c := range dep.ConflictsWith {
+		if !uuidRe.MatchString(c.UUID) {
+			v.problem(SeverityError, fmt.Sprintf("conflictsWith[%d].uuid", i), "%q is not a valid uuid", c.UUID)
+		}
+	}
+
+	for i, d := range dep.JavaDependencies {
+		artifact(fmt.Sprintf("javaDependencies[%d]", i), item("javaDependencies", i), d.GroupId, d.ArtifactId, d.Version)
+	}
+	for i, d := range dep.JniDependencies {
+		path := fmt.Sprintf("jniDependencies[%d]", i)
+		artifact(path, item("jniDependencies", i), d.GroupId, d.ArtifactId, d.Version)
+		platforms(path + ".validPlatforms", d.ValidPlatforms)
+		simMode(path + ".simMode", d.SimMode)
+	}
+	for i, d := range dep.CppDependencies {
+		path := fmt.Sprintf("cppDependencies[%d]", i)
+		artifact(path, item("cppDependencies", i), d.GroupId, d.ArtifactId, d.Version)
+		platforms(path + ".binaryPlatforms", d.BinaryPlatforms)
+		simMode(path + ".simMode", d.SimMode)
+	}
+
+	return v.problems
+}
@@ -0,0 +1,194 @@
+package vendordep
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// validVendordep a vendordep which follows the schema exactly, as a map so
+// tests can break it
+func validVendordep() map[string]any {
+	return map[string]any{
+		"fileName": "REVLib.json",
+		"name": "REVLib",
+		"version": "2025.0.3",
+		"frcYear": "2025",
+		"uuid": "3f48eb8c-50fe-43a6-9cb7-44c86353c4cb",
+		"mavenUrls": []any{ "https://maven.revrobotics.com/" },
+		"jsonUrl": "https://software-metadata.revrobotics.com/REVLib-2025.json",
+		"javaDependencies": []any{
+			map[string]any{ "groupId": "com.revrobotics.frc", "artifactId": "REVLib-java", "version": "2025.0.3" },
+		},
+		"jniDependencies": []any{
+			map[string]any{
+				"groupId": "com.revrobotics.frc",
+				"artifactId": "REVLib-driver",
+				"version": "2025.0.3",
+				"isJar": false,
+				"skipInvalidPlatforms": true,
+				"validPlatforms": []any{ "linuxathena", "linuxx86-64" },
+			},
+		},
+		"cppDependencies": []any{
+			map[string]any{
+				"groupId": "com.revrobotics.frc",
+				"artifactId": "REVLib-cpp",
+				"version": "2025.0.3",
+				"libName": "REVLib",
+				"headerClassifier": "headers",
+				"sharedLibrary": false,
+				"skipInvalidPlatforms": true,
+				"binaryPlatforms": []any{ "linuxathena", "windowsx86-64" },
+			},
+		},
+	}
+}
+
+func jniDep(dep map[string]any) map[string]any {
+	return dep["jniDependencies"].([]any)[0].(map[string]any)
+}
+
+func cppDep(dep map[string]any) map[string]any {
+	return dep["cppDependencies"].([]any)[0].(map[string]any)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		change func(dep map[string]any)
+		// the start of each problem's message, nil when the vendordep is
+		// valid
+		want []string
+	}{
+		{ "valid", func(dep map[string]any) {}, nil },
+		{ "extraValues", func(dep map[string]any) {
+			dep["extraValues"] = map[string]any{ "anything": []any{ 1, "two" } }
+		}, nil },
+		{ "extraValues of any type", func(dep map[string]any) {
+			dep["extraValues"] = "a string"
+		}, nil },
+		{ "rphMetadata", func(dep map[string]any) {
+			dep[MetadataKey] = map[string]any{ "edited": true }
+		}, nil },
+		{ "rphMetadata must be an object", func(dep map[string]any) {
+			dep[MetadataKey] = "edited"
+		}, []string{ "rphMetadata: must be an object" } },
+		{ "sim modes", func(dep map[string]any) {
+			jniDep(dep)["simMode"] = SimModeHardware
+			cppDep(dep)["simMode"] = SimModeSoftware
+		}, nil },
+
+		{ "unknown top level field", func(dep map[string]any) {
+			dep["website"] = "https://revrobotics.com"
+		}, []string{ "website: unknown field" } },
+		{ "unknown nested field", func(dep map[string]any) {
+			jniDep(dep)["platforms"] = []any{ "linuxathena" }
+		}, []string{ "jniDependencies[0].platforms: unknown field" } },
+		{ "missing required fields", func(dep map[string]any) {
+			delete(dep, "uuid")
+			delete(dep, "mavenUrls")
+			delete(cppDep(dep), "libName")
+		}, []string{
+			"mavenUrls: missing required field",
+			"uuid: missing required field",
+			"cppDependencies[0].libName: missing required field",
+		} },
+		{ "optional fields", func(dep map[string]any) {
+			cppDep(dep)["configuration"] = "debug"
+			cppDep(dep)["sourcesClassifier"] = "sources"
+			dep["requires"] = []any{
+				map[string]any{
+					"uuid": "fd4d2b5f-7bf2-4f57-9e8b-3a9a0d6f0d8e",
+					"errorMessage": "needs phoenix",
+					"offlineFileName": "Phoenix6.json",
+					"onlineUrl": "https://maven.ctr-electronics.com/release/com/ctre/phoenix6/latest/Phoenix6-frc2025-latest.json",
+				},
+			}
+		}, nil },
+
+		{ "string as a number", func(dep map[string]any) {
+			dep["version"] = 2025
+		}, []string{ "version: must be a string" } },
+		{ "bool as a string", func(dep map[string]any) {
+			jniDep(dep)["isJar"] = "false"
+		}, []string{ "jniDependencies[0].isJar: must be a boolean" } },
+		{ "list as a string", func(dep map[string]any) {
+			dep["mavenUrls"] = "https://maven.revrobotics.com/"
+		}, []string{ "mavenUrls: must be a list of strings" } },
+		{ "object list of strings", func(dep map[string]any) {
+			dep["javaDependencies"] = []any{ "com.revrobotics.frc:REVLib-java:2025.0.3" }
+		}, []string{ "javaDependencies: must be a list of objects" } },
+		{ "null", func(dep map[string]any) {
+			dep["name"] = nil
+		}, []string{ "name: must be a string" } },
+		{ "frcYear as a number", func(dep map[string]any) {
+			dep["frcYear"] = 2025
+		}, nil },
+
+		{ "bad uuid", func(dep map[string]any) {
+			dep["uuid"] = "not-a-uuid"
+		}, []string{ `uuid: "not-a-uuid" is not a valid uuid` } },
+		{ "bad requires uuid", func(dep map[string]any) {
+			dep["requires"] = []any{
+				map[string]any{ "uuid": "x", "errorMessage": "needs x", "offlineFileName": "x.json" },
+			}
+		}, []string{ `requires[0].uuid: "x" is not a valid uuid` } },
+		{ "bad frcYear", func(dep map[string]any) {
+			dep["frcYear"] = "25"
+		}, []string{ `frcYear: "25" is not a year` } },
+		{ "bad platform", func(dep map[string]any) {
+			cppDep(dep)["binaryPlatforms"] = []any{ "linuxathena", "roborio" }
+		}, []string{ `cppDependencies[0].binaryPlatforms[1]: unknown platform "roborio"` } },
+		{ "bad simMode", func(dep map[string]any) {
+			jniDep(dep)["simMode"] = "sim"
+		}, []string{ `jniDependencies[0].simMode: unknown sim mode "sim"` } },
+		{ "fileName is a path", func(dep map[string]any) {
+			dep["fileName"] = "../REVLib.json"
+		}, []string{ "fileName: must not be a path" } },
+	}
+
+	for _, tt := range tests {
+		dep := validVendordep()
+		tt.change(dep)
+		data, err := json.Marshal(dep)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		problems := Validate("REVLib.json", data)
+		if len(problems) != len(tt.want) {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, problems, tt.want)
+			continue
+		}
+		for i, p := range problems {
+			if !strings.HasPrefix(p.Message, tt.want[i]) || p.File != "REVLib.json" {
+				t.Errorf("%s: Validate()[%d] = %v, want %q", tt.name, i, p, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestValidateInvalidJson(t *testing.T) {
+	for _, data := range []string{ `{"name":`, `[]`, `"REVLib"` } {
+		problems := Validate("REVLib.json", []byte(data))
+		if len(problems) != 1 || problems[0].Severity != SeverityError {
+			t.Errorf("Validate(%s) = %v, want a single error", data, problems)
+		}
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	dep := validVendordep()
+	dep["jsonUrl"] = ""
+	dep["version"] = "latest"
+	data, err := json.Marshal(dep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems := Validate("REVLib.json", data)
+	if len(problems) != 2 || HasErrors(problems) {
+		t.Errorf("Validate = %v, want two warnings", problems)
+	}
+}
@@ -3,10 +3,13 @@ package vendordep
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
 	"rph/utils"
+	"slices"
+	"strings"
 )
 
 type JavaDepedency struct {
@@ -37,8 +40,23 @@ type CppDependency struct {
 	SimMode string `json:"simMode"`
 }
 
+// Requirement another vendordep which has to be installed alongside this one
+type Requirement struct {
+	UUID string `json:"uuid"`
+	ErrorMessage string `json:"errorMessage"`
+	OfflineFileName string `json:"offlineFileName"`
+	OnlineUrl string `json:"onlineUrl,omitempty"`
+}
+
+// Conflict a vendordep which can't be installed alongside this one
+type Conflict struct {
+	UUID string `json:"uuid"`
+	ErrorMessage string `json:"errorMessage"`
+	OfflineFileName string `json:"offlineFileName"`
+}
+
 type Vendordep struct {
-	FileName string `json:"filename"`
+	FileName string `json:"fileName"`
 	Name string `json:"name"`
 	Version string `json:"version"`
 	FrcYear utils.StringOrNumber `json:"frcYear"`
@@ -48,14 +66,28 @@ type Vendordep struct {
 	JavaDependencies []JavaDepedency `json:"javaDependencies"`
 	JniDependencies []JniDependency `json:"jniDependencies"`
 	CppDependencies []CppDependency `json:"cppDependencies"`
+	Requires []Requirement `json:"requires,omitempty"`
+	ConflictsWith []Conflict `json:"conflictsWith,omitempty"`
+
+	// Path where the vendordep file was found in the project, this is only set
+	// by ListVendorDeps
+	Path string `json:"-"`
+}
+
+// sameVersion check if two versions are the same ignoring any "v" prefix
+func sameVersion(a string, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
 }
 
-// Matches check if one vendor dep matches another in any real useful way
+// Matches check if one vendor dep matches another in any real useful way. A
+// strict match requires the same name and version or the same uuid, a loose
+// match also accepts the same jsonUrl or the same name with any version.
 func (v *Vendordep) Matches(other Vendordep, strict bool) bool {
-	if other.Name != "" && v.Name == other.Name {
-		if other.Version != "" && v.Version == other.Version {
+	if other.Name != "" && strings.EqualFold(v.Name, other.Name) {
+		if other.Version != "" && sameVersion(v.Version, other.Version) {
 			return true
 		}
+		if !strict && other.Version == "" { return true }
 	}
 	if other.UUID != "" && v.UUID == other.UUID { return true }
 	if !strict {
@@ -65,6 +97,28 @@ func (v *Vendordep) Matches(other Vendordep, strict bool) bool {
 	return false
 }
 
+// MissingRequirements find the vendordeps dep requires which aren't in deps
+func (v *Vendordep) MissingRequirements(deps []Vendordep) []Requirement {
+	var missing []Requirement
+	for _, r := range v.Requires {
+		if !slices.ContainsFunc(deps, func(d Vendordep) bool { return d.UUID == r.UUID }) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// Dependents find the vendordeps in deps which require the vendordep with uuid
+func Dependents(deps []Vendordep, uuid string) []Vendordep {
+	var dependents []Vendordep
+	for _, d := range deps {
+		if slices.ContainsFunc(d.Requires, func(r Requirement) bool { return r.UUID == uuid }) {
+			dependents = append(dependents, d)
+		}
+	}
+	return dependents
+}
+
 func Parse(vendordepFile io.Reader) (*Vendordep, error) {
 	var vendordep Vendordep
 
@@ -83,6 +137,8 @@ func ListVendorDeps(projectFs fs.FS) ([]Vendordep, error) {
 		err error) error {
 			if err != nil { return err }
 			if d.IsDir() { return nil }
+			// the vendordep directory also holds our lockfile
+			if !strings.HasSuffix(path, ".json") { return nil }
 
 			file, err := projectFs.Open(path)
 			if err != nil {
@@ -97,6 +153,7 @@ func ListVendorDeps(projectFs fs.FS) ([]Vendordep, error) {
 				return err
 			}
 
+			dep.Path = path
 			out = append(out, *dep)
 			return nil
 		})
@@ -125,5 +182,43 @@ func FindVendorDepFromName(name string, fs fs.FS) (*Vendordep, error) {
 	return nil, errors.New("Vendordep not found")
 }
 
-func ShowInfo(Vendordep) {
+// ShowInfo write out everything we know about a vendordep in a human readable
+// format
+func ShowInfo(w io.Writer, dep Vendordep) {
+	fmt.Fprintf(w, "Name: %s\n", dep.Name)
+	fmt.Fprintf(w, "Version: %s\n", dep.Version)
+	fmt.Fprintf(w, "File Name: %s\n", dep.FileName)
+	fmt.Fprintf(w, "UUID: %s\n", dep.UUID)
+	fmt.Fprintf(w, "FRC Year: %s\n", dep.FrcYear)
+	fmt.Fprintf(w, "JSON URL: %s\n", dep.JsonUrl)
+
+	fmt.Fprintln(w, "Maven URLs:")
+	for _, url := range dep.MavenUrls {
+		fmt.Fprintf(w, "  %s\n", url)
+	}
+
+	fmt.Fprintln(w, "Java Dependencies:")
+	for _, d := range dep.JavaDependencies {
+		fmt.Fprintf(w, "  %s:%s:%s\n", d.GroupId, d.ArtifactId, d.Version)
+	}
+
+	fmt.Fprintln(w, "JNI Dependencies:")
+	for _, d := range dep.JniDependencies {
+		fmt.Fprintf(w, "  %s:%s:%s\n", d.GroupId, d.ArtifactId, d.Version)
+		fmt.Fprintf(w, "    Platforms: %s\n", strings.Join(d.ValidPlatforms, ", "))
+		fmt.Fprintf(w, "    Sim Mode: %s\n", d.SimMode)
+		fmt.Fprintf(w, "    Is Jar: %t\n", d.IsJar)
+		fmt.Fprintf(w, "    Skip Invalid Platforms: %t\n", d.SkipInvalidPlatforms)
+	}
+
+	fmt.Fprintln(w, "C++ Dependencies:")
+	for _, d := range dep.CppDependencies {
+		fmt.Fprintf(w, "  %s:%s:%s\n", d.GroupId, d.ArtifactId, d.Version)
+		fmt.Fprintf(w, "    Library Name: %s\n", d.LibName)
+		fmt.Fprintf(w, "    Header Classifier: %s\n", d.HeaderClassifier)
+		fmt.Fprintf(w, "    Platforms: %s\n", strings.Join(d.BinaryPlatforms, ", "))
+		fmt.Fprintf(w, "    Sim Mode: %s\n", d.SimMode)
+		fmt.Fprintf(w, "    Shared Library: %t\n", d.SharedLibrary)
+		fmt.Fprintf(w, "    Skip Invalid Platforms: %t\n", d.SkipInvalidPlatforms)
+	}
 }
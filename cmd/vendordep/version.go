@@ -0,0 +1,146 @@
+package vendordep
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// matches versions like 2025.3.1, v1.2, 25.1.0.4 and 2025.0.0-beta-3
+var versionRe = regexp.MustCompile(`^[vV]?(\d+(?:\.\d+){0,3})(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Version a parsed vendordep version, vendors don't all follow semver so this
+// is a bit more lenient than semver is.
+type Version struct {
+	// Parts the numeric parts of the version, there are up to four of them
+	Parts []int
+	// Pre the pre-release tag, e.g. "beta-3"
+	Pre string
+	original string
+}
+
+func ParseVersion(s string) (Version, error) {
+	matches := versionRe.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return Version{}, errors.New("invalid version: " + s)
+	}
+
+	var parts []int
+	for _, p := range strings.Split(matches[1], ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return Version{}, errors.New("invalid version: " + s)
+		}
+		parts = append(parts, n)
+	}
+
+	return Version{ Parts: parts, Pre: matches[2], original: s }, nil
+}
+
+func (v Version) String() string {
+	return v.original
+}
+
+// IsPreRelease check if the version is a beta, alpha, release candidate, etc.
+func (v Version) IsPreRelease() bool {
+	return v.Pre != ""
+}
+
+// Compare returns -1 if v is older than other, 1 if v is newer than other and 0
+// if they're the same. Missing parts are treated as zero so 2025.1 is the same
+// as 2025.1.0, and a pre-release is older than the release it comes before.
+func (v Version) Compare(other Version) int {
+	for i := 0; i < max(len(v.Parts), len(other.Parts)); i++ {
+		var x, y int
+		if i < len(v.Parts) { x = v.Parts[i] }
+		if i < len(other.Parts) { y = other.Parts[i] }
+
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+
+	if v.Pre == other.Pre {
+		return 0
+	} else if v.Pre == "" {
+		return 1
+	} else if other.Pre == "" {
+		return -1
+	}
+
+	return comparePreRelease(v.Pre, other.Pre)
+}
+
+// comparePreRelease compare pre-release tags the way semver does, numeric
+// identifiers are compared as numbers and sort before words.
+func comparePreRelease(a, b string) int {
+	split := func(r rune) bool { return r == '.' || r == '-' }
+	aIds := strings.FieldsFunc(a, split)
+	bIds := strings.FieldsFunc(b, split)
+
+	for i := 0; i < min(len(aIds), len(bIds)); i++ {
+		x, xErr := strconv.Atoi(aIds[i])
+		y, yErr := strconv.Atoi(bIds[i])
+
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				if x < y { return -1 }
+				return 1
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aIds[i], bIds[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	if len(aIds) < len(bIds) {
+		return -1
+	} else if len(aIds) > len(bIds) {
+		return 1
+	}
+	return 0
+}
+
+var versionPartRe = regexp.MustCompile(`\d+`)
+
+// compareVersions compare two version strings, versions which can't be parsed
+// fall back to comparing just their numbers.
+func compareVersions(a, b string) int {
+	va, aErr := ParseVersion(a)
+	vb, bErr := ParseVersion(b)
+	if aErr == nil && bErr == nil {
+		return va.Compare(vb)
+	}
+
+	aParts := versionPartRe.FindAllString(a, -1)
+	bParts := versionPartRe.FindAllString(b, -1)
+
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var x, y int
+		if i < len(aParts) { x, _ = strconv.Atoi(aParts[i]) }
+		if i < len(bParts) { y, _ = strconv.Atoi(bParts[i]) }
+
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// isPreRelease check if a version string is a pre-release
+func isPreRelease(version string) bool {
+	v, err := ParseVersion(version)
+	return err == nil && v.IsPreRelease()
+}
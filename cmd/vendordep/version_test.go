@@ -0,0 +1,105 @@
+package vendordep
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in string
+		parts []int
+		pre string
+	}{
+		{ "2025.3.1", []int{ 2025, 3, 1 }, "" },
+		{ "v1.2", []int{ 1, 2 }, "" },
+		{ "25.1.0.4", []int{ 25, 1, 0, 4 }, "" },
+		{ "2025.0.0-beta-3", []int{ 2025, 0, 0 }, "beta-3" },
+		{ "1.0.0+build.5", []int{ 1, 0, 0 }, "" },
+	}
+
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.in)
+		if err != nil {
+			t.Errorf("ParseVersion(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if !slices.Equal(v.Parts, tt.parts) || v.Pre != tt.pre {
+			t.Errorf("ParseVersion(%q) = %v %q, want %v %q", tt.in, v.Parts, v.Pre, tt.parts, tt.pre)
+		}
+		if v.String() != tt.in {
+			t.Errorf("ParseVersion(%q).String() = %q", tt.in, v.String())
+		}
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, in := range []string{ "", "latest", "1.2.3.4.5", "2025.x", "1..2" } {
+		if _, err := ParseVersion(in); err == nil {
+			t.Errorf("ParseVersion(%q) should have failed", in)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{ "2025.3.1", "2025.3.1", 0 },
+		{ "2025.1", "2025.1.0", 0 },
+		{ "v2025.3.1", "2025.3.1", 0 },
+		{ "2025.3.10", "2025.3.9", 1 },
+		{ "2025.2.0", "2025.10.0", -1 },
+		{ "2025.0.0-beta-1", "2025.0.0", -1 },
+		{ "2025.0.0-beta-2", "2025.0.0-beta-10", -1 },
+		{ "2025.0.0-alpha", "2025.0.0-beta", -1 },
+		{ "1.0.0-1", "1.0.0-alpha", -1 },
+		{ "1.0.0-rc", "1.0.0-rc.1", -1 },
+		// not versions at all, only the numbers are compared
+		{ "release_7", "release_12", -1 },
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	var deps []OnlineVendordep
+	for _, v := range []string{ "2025.0.2", "2025.0.10", "2025.1.0-beta-1", "2025.0.3", "2025.1.0" } {
+		deps = append(deps, OnlineVendordep{ Version: v })
+	}
+
+	SortVersions(deps)
+
+	var got []string
+	for _, d := range deps {
+		got = append(got, d.Version)
+	}
+	want := []string{ "2025.1.0", "2025.1.0-beta-1", "2025.0.10", "2025.0.3", "2025.0.2" }
+	if !slices.Equal(got, want) {
+		t.Errorf("SortVersions = %v, want %v", got, want)
+	}
+}
+
+func TestLatest(t *testing.T) {
+	deps := []OnlineVendordep{ { Version: "2025.1.0-beta-1" }, { Version: "2025.0.3" } }
+	if got := Latest(deps); got == nil || got.Version != "2025.0.3" {
+		t.Errorf("Latest should prefer releases, got %v", got)
+	}
+
+	deps = []OnlineVendordep{ { Version: "2025.1.0-beta-1" }, { Version: "2025.1.0-beta-2" } }
+	if got := Latest(deps); got == nil || got.Version != "2025.1.0-beta-2" {
+		t.Errorf("Latest should fall back to pre-releases, got %v", got)
+	}
+
+	if got := Latest(nil); got != nil {
+		t.Errorf("Latest(nil) = %v, want nil", got)
+	}
+}
@@ -1,51 +1,297 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
-	"net/http"
 	"os"
 	"path/filepath"
-	"rph/cmd/template"
 	"rph/cmd/vendordep"
-	"rph/cmd/vendordep/artifactory"
-	"rph/utils"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// fetchOnline get a vendordep from a url or the marketplace, the url it was
+// downloaded from is returned alongside it. Names from the marketplace resolve
+// to the newest version c allows.
+func fetchOnline(year string, arg string, c vendordep.Constraint) ([]byte, *vendordep.Vendordep, string, error) {
+	if strings.HasPrefix(arg, "http") {
+		data, dep, err := vendordep.FetchVendorDep(arg)
+		return data, dep, arg, err
+	}
+
+	var online *vendordep.OnlineVendordep
+	var err error
+	if c.Any() {
+		online, err = vendordep.FindAvailableOnlineDep(year, arg)
+	} else {
+		online, err = vendordep.ResolveOnlineDep(year, arg, c)
+	}
+	if err != nil {
+		slog.Error("Unable to find vendordep", "name", arg, "error", err)
+		return nil, nil, "", err
+	}
+
+	return online.Fetch()
+}
+
+// fetchOffline get the best match for a vendordep from the cache, unless strict
+// is set any version of the vendordep c allows will do when the exact one can't
+// be found.
+func fetchOffline(arg string, strict bool, c vendordep.Constraint) ([]byte, *vendordep.Vendordep, error) {
+	query := vendordep.ParseQuery(arg)
+
+	// a name with a constraint asks for any cached version the constraint
+	// allows, not a specific one
+	exact := query.Version != "" || c.Any()
+	data, dep, err := vendordep.FindCachedVendorDep(query, exact, c)
+	if err != nil && !strict {
+		loose := query
+		loose.Version = ""
+		data, dep, err = vendordep.FindCachedVendorDep(loose, false, c)
+		if err == nil && query.Version != "" {
+			slog.Warn("Exact vendordep is not cached, using the closest match", "name", arg, "version", dep.Version)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("Using vendordep from the cache", "name", dep.Name, "version", dep.Version)
+	return data, dep, nil
+}
+
+// fetchArg get the vendordep for an argument to add from the marketplace, a url
+// or the cache. The url it was downloaded from and the version constraint to
+// lock it to are returned alongside it.
+func fetchArg(year string, arg string, offline bool, strict bool) ([]byte, *vendordep.Vendordep, string, string, error) {
+	name, rawConstraint, hasConstraint := vendordep.SplitConstraint(arg)
+
+	var constraint vendordep.Constraint
+	if hasConstraint {
+		var err error
+		constraint, err = vendordep.ParseConstraint(rawConstraint)
+		if err != nil {
+			slog.Error("Invalid version constraint", "name", arg, "error", err)
+			return nil, nil, "", "", err
+		}
+	}
+
+	query := vendordep.ParseQuery(name)
+
+	var data []byte
+	var dep *vendordep.Vendordep
+	var source string
+	var err error
+
+	// the marketplace has no way to look up a uuid so those always come from
+	// the cache
+	if offline || query.UUID != "" {
+		data, dep, err = fetchOffline(name, strict, constraint)
+	} else {
+		data, dep, source, err = fetchOnline(year, name, constraint)
+		if vendordep.IsNetworkError(err) {
+			slog.Warn("Unable to reach the network, installing from the cache instead", "name", arg)
+			data, dep, err = fetchOffline(name, strict, constraint)
+		}
+	}
+	if err != nil {
+		slog.Error("Failed to get vendordep", "name", arg, "error", err)
+		return nil, nil, "", "", err
+	}
+
+	// urls and the cache can't be searched by constraint so just make sure
+	// what we got satisfies it
+	if !constraint.Allows(dep.Version) {
+		slog.Error("Vendordep does not satisfy the version constraint", "name", dep.Name, "version", dep.Version, "constraint", constraint)
+		return nil, nil, "", "", errors.New("no version of " + name + " matches " + constraint.String())
+	}
+
+	lockConstraint := ""
+	if hasConstraint {
+		lockConstraint = constraint.String()
+	}
+
+	return data, dep, source, lockConstraint, nil
+}
+
+// isLocalVendorDep check if an argument to add refers to a file on disk, "-"
+// is stdin
+func isLocalVendorDep(arg string) bool {
+	if strings.HasPrefix(arg, "http") {
+		return false
+	}
+	if arg == "-" || strings.ContainsRune(arg, filepath.Separator) || strings.ContainsRune(arg, '/') {
+		return true
+	}
+
+	info, err := os.Stat(arg)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// readLocal read a vendordep from a file, or from stdin when path is "-"
+func readLocal(stdin io.Reader, path string) ([]byte, *vendordep.Vendordep, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dep, err := vendordep.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, dep, nil
+}
+
+// fetchRequirement get a vendordep another vendordep requires, from its
+// onlineUrl when it has one and otherwise from the cache
+func fetchRequirement(r vendordep.Requirement) ([]byte, *vendordep.Vendordep, string, error) {
+	if r.OnlineUrl != "" {
+		data, dep, err := vendordep.FetchVendorDep(r.OnlineUrl)
+		if err == nil && dep.UUID != r.UUID {
+			return nil, nil, "", fmt.Errorf("%s is not the required vendordep, it has the uuid %s", r.OnlineUrl, dep.UUID)
+		} else if err == nil {
+			return data, dep, r.OnlineUrl, nil
+		} else if !vendordep.IsNetworkError(err) {
+			return nil, nil, "", err
+		}
+		slog.Warn("Unable to reach the network, installing from the cache instead", "uuid", r.UUID)
+	}
+
+	data, dep, err := vendordep.FindCachedVendorDep(vendordep.Vendordep{ UUID: r.UUID }, true, vendordep.Constraint{})
+	return data, dep, "", err
+}
+
+// pendingVendordep a vendordep which is going to be installed once every
+// vendordep being added has been checked
+type pendingVendordep struct {
+	data []byte
+	dep *vendordep.Vendordep
+	source string
+	constraint string
+}
+
+// collectRequirements fetch the vendordeps dep requires which aren't in the
+// project yet, the user is asked first unless yes is set. Requirements are
+// returned before the vendordeps which need them. The uuid and file name of
+// every vendordep which is already being installed is kept in visited so
+// requirements which go around in a circle are only installed once.
+func collectRequirements(dep *vendordep.Vendordep, yes bool, visited map[string]bool) ([]pendingVendordep, error) {
+	installed, err := vendordep.ListVendorDeps(projectFs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{ dep.UUID, dep.FileName } {
+		if key != "" {
+			visited[key] = true
+		}
+	}
+
+	var pending []pendingVendordep
+	for _, r := range dep.MissingRequirements(installed) {
+		name := r.OfflineFileName
+		if name == "" {
+			name = r.UUID
+		}
+
+		if (r.UUID != "" && visited[r.UUID]) || (r.OfflineFileName != "" && visited[r.OfflineFileName]) {
+			slog.Debug("Requirement is already being installed", "name", dep.Name, "requires", name)
+			continue
+		}
+
+		install := yes
+		if !install {
+			install, err = confirm(fmt.Sprintf("%s requires %s, install it?", dep.Name, name), r.ErrorMessage)
+			if err != nil { return nil, err }
+		}
+		// checkNewVendorDeps warns about whatever wasn't installed
+		if !install { continue }
+
+		data, req, source, err := fetchRequirement(r)
+		if err != nil {
+			slog.Error("Failed to get required vendordep", "name", dep.Name, "requires", name, "error", err)
+			return nil, err
+		}
+
+		// requirements can have requirements of their own
+		reqs, err := collectRequirements(req, yes, visited)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, reqs...)
+		pending = append(pending, pendingVendordep{ data: data, dep: req, source: source })
+	}
+
+	return pending, nil
+}
+
 // vendordepaddCmd represents the vendordep add command
 var vendordepaddCmd = &cobra.Command{
 	Use: "add",
 	Short: "Add a new vendordep",
 	Long: `Add a new vendordep. You may pass in as many urls or vendordep names
 as you wish. The vendordep names are determined by what's found at
-https://frcmaven.wpi.edu/ui/native/vendordeps/`,
+https://frcmaven.wpi.edu/ui/native/vendordeps/
+
+When the network can't be reached, or --offline is passed, vendordeps are
+installed from the rph cache instead. Names, uuids and jsonUrls are all
+matched against every cached vendordep. By default if the exact version
+isn't cached the newest cached version is used, pass --strict to only accept
+an exact name and version or uuid match.
+
+A version constraint may be given after an @, the newest version which
+satisfies it is installed and the constraint is kept in the lockfile so that
+rph vendordep update won't go outside of it. ^2025.3 allows any 2025 version
+from 2025.3 onwards, ~25.1 allows any 25.1.x version and latest allows
+anything. Exact versions and >=, >, <=, < are supported as well.
+
+Vendordep files on disk may be installed by passing their path, or - to read
+one from stdin. They're copied into the rph cache so that they can be
+installed offline later.
+
+Before anything is installed it's checked against your project the same way
+rph vendordep check does, pass --force to install it anyways. This includes
+vendordeps which declare a conflict with one you've already installed.
+
+When a vendordep requires other vendordeps which aren't installed you're asked
+if they should be installed too, pass --yes to install them without asking.
+When rph can't ask, because it's not running in a terminal, you'll only be
+warned about them.
+
+Examples:
+  rph vendordep add photonlib # The latest version
+  rph vendordep add photonlib-v2025.3.1
+  rph vendordep add 'photonlib@^2025.3'
+  rph vendordep add 'phoenix6@~25.1'
+  rph vendordep add --offline photonlib
+  rph vendordep add --offline 515fe07e-bfc6-11fa-b3de-0242ac130004
+  rph vendordep add ./MyLib.json
+  cat MyLib.json | rph vendordep add -`,
 	Args: cobra.MinimumNArgs(1),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
-		year, err := cmd.Flags().GetString("year")
+		if strings.HasPrefix(toComplete, ".") || strings.ContainsRune(toComplete, '/') {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		year, err := projectYear(cmd)
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 
-		if year == "" {
-			file, err := os.Open(filepath.Join(projectDir, ".wpilib", "wpilib_preferences.json"))
-			if err != nil {
-				return nil, cobra.ShellCompDirectiveNoFileComp
-			}
-			defer file.Close()
-
-			var wpilibPrefs template.WpilibPreferences
-			if err := json.NewDecoder(file).Decode(&wpilibPrefs); err != nil {
-				return nil, cobra.ShellCompDirectiveNoFileComp
-			}
-
-			year = wpilibPrefs.Year
-		}
+		// completions don't run PersistentPreRun
+		setupArtifactory(cmd)
 
-		// TODO: refactor this into it's own func, cache it and then we can make
-		// less api calls
 		validVendordeps, err := vendordep.ListAvailableOnlineDeps(year)
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
@@ -53,6 +299,10 @@ https://frcmaven.wpi.edu/ui/native/vendordeps/`,
 
 		var completions []string
 		for k, deps := range validVendordeps {
+			if strings.HasPrefix(k, toComplete) {
+				completions = append(completions, k)
+			}
+
 			for _, dep := range deps {
 				comp := k + "-" + dep.Version
 				if strings.HasPrefix(comp, toComplete) {
@@ -66,71 +316,62 @@ https://frcmaven.wpi.edu/ui/native/vendordeps/`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !inProjectDir() { return nil }
 
-		year, err := cmd.Flags().GetString("year")
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil { return err }
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil { return err }
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil { return err }
+		yes, err := cmd.Flags().GetBool("yes")
 		if err != nil { return err }
 
-		if year == "" {
-			file, err := os.Open(filepath.Join(projectDir, ".wpilib", "wpilib_preferences.json"))
-			if err != nil {
-				slog.Error("Failed to open wpilib_preferences.json", "error", err)
-				return err
-			}
-			defer file.Close()
-
-			var wpilibPrefs template.WpilibPreferences
-			if err := json.NewDecoder(file).Decode(&wpilibPrefs); err != nil {
-				slog.Error("Failed to decode wpilib_preferences.json", "error", err)
-				return err
-			}
-
-			year = wpilibPrefs.Year
+		year, err := projectYear(cmd)
+		if err != nil {
+			slog.Error("Failed to get the project year", "error", err)
+			return err
 		}
 
-		fsys := artifactory.New(artifactory.DefaultVendorDepArtifactoryUrl)
-		path := "vendordeps/vendordep-marketplace/" + year
-
-		// make sure the vendordep directory exists in the current project
-		os.MkdirAll(filepath.Join(projectDir, "vendordeps"), 0755);
-
 		for _, arg := range args {
-			if strings.HasPrefix(arg, "http") {
-				resp, err := http.Get(arg)
-				if err != nil {
-					slog.Error("Failed to download file", "url", arg, "error", err)
-					return err
-				}
-				defer resp.Body.Close()
+			var data []byte
+			var dep *vendordep.Vendordep
+			var source, lockConstraint string
 
-				dep, err := vendordep.Parse(resp.Body)
+			if isLocalVendorDep(arg) {
+				data, dep, err = readLocal(cmd.InOrStdin(), arg)
 				if err != nil {
-					slog.Error("Failed to parse vendor dep", "error", err)
+					slog.Error("Failed to read vendordep", "file", arg, "error", err)
 					return err
 				}
-
-				err = utils.DownloadFile(arg, filepath.Join(projectDir, "vendordeps", dep.FileName))
-				if err != nil {
-					slog.Error("Failed to download vendordep", "error", err)
-				}
 			} else {
-				vendordeps, err := vendordep.ListAvailableOnlineDeps(year)
+				data, dep, source, lockConstraint, err = fetchArg(year, arg, offline, strict)
+				if err != nil { return err }
+			}
+
+			// nothing is installed until the vendordep and everything it
+			// requires has been checked
+			pending, err := collectRequirements(dep, yes, map[string]bool{})
+			if err != nil { return err }
+			pending = append(pending, pendingVendordep{ data: data, dep: dep, source: source, constraint: lockConstraint })
+
+			var deps []*vendordep.Vendordep
+			for _, p := range pending {
+				deps = append(deps, p.dep)
+			}
+			if err := checkNewVendorDeps(deps, force); err != nil {
+				return err
+			}
+
+			for _, p := range pending {
+				installedDep, err := vendordep.Install(projectDir, p.data, p.source, p.constraint)
 				if err != nil {
+					slog.Error("Failed to install vendordep", "name", p.dep.Name, "error", err)
 					return err
 				}
 
-				for k, deps := range vendordeps {
-					for _, dep := range deps {
-						d := k + "-" + dep.Version
-						if d == arg {
-							url := fsys.GetUrl(path + "/" + dep.FileName)
-							err := utils.DownloadFile(url, filepath.Join(projectDir, "vendordeps", dep.FileName))
-							if err != nil {
-								slog.Error("Failed to copy the file to the filesystem", "error", err)
-								return err
-							}
-
-							break
-						}
-					}
+				if p.dep == dep {
+					slog.Info("Installed vendordep", "name", installedDep.Name, "version", installedDep.Version)
+				} else {
+					slog.Info("Installed required vendordep", "name", installedDep.Name, "version", installedDep.Version, "for", dep.Name)
 				}
 			}
 		}
@@ -144,4 +385,8 @@ https://frcmaven.wpi.edu/ui/native/vendordeps/`,
 func init() {
 	vendordepCmd.AddCommand(vendordepaddCmd)
 	vendordepaddCmd.Flags().StringP("year", "y", "", "override the year to search for dependencies in frcmaven.")
+	vendordepaddCmd.Flags().Bool("offline", false, "Install vendordeps from the rph cache without using the network.")
+	vendordepaddCmd.Flags().Bool("strict", false, "Only install an exact name and version or uuid match from the cache.")
+	vendordepaddCmd.Flags().BoolP("force", "f", false, "Install vendordeps even if they aren't compatible with the project.")
+	vendordepaddCmd.Flags().Bool("yes", false, "Install vendordeps required by the new vendordeps without asking.")
 }
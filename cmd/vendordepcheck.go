@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+func logProblems(problems []vendordep.Problem) {
+	for _, p := range problems {
+		if p.Severity == vendordep.SeverityError {
+			slog.Error(p.Message, "file", p.File)
+		} else {
+			slog.Warn(p.Message, "file", p.File)
+		}
+	}
+}
+
+// checkNewVendorDeps make sure vendordeps can be added to the current project
+// together without breaking it, any problems which already existed are
+// ignored.
+func checkNewVendorDeps(newDeps []*vendordep.Vendordep, force bool) error {
+	year, err := wpilibYear()
+	if err != nil {
+		slog.Warn("Unable to get the project year, skipping checks", "error", err)
+		return nil
+	}
+
+	installed, err := vendordep.ListVendorDeps(projectFs)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]bool{}
+	for _, p := range vendordep.Check(installed, year) {
+		existing[p.String()] = true
+	}
+
+	// installing only replaces the file with the same name, a vendordep with
+	// the same uuid under another name stays and is reported as a duplicate
+	var deps []vendordep.Vendordep
+	var names []string
+	for _, d := range installed {
+		if slices.ContainsFunc(newDeps, func(n *vendordep.Vendordep) bool { return n.FileName == d.FileName }) { continue }
+		deps = append(deps, d)
+	}
+	for _, d := range newDeps {
+		deps = append(deps, *d)
+		names = append(names, d.Name)
+	}
+
+	var problems []vendordep.Problem
+	for _, p := range vendordep.Check(deps, year) {
+		if !existing[p.String()] {
+			problems = append(problems, p)
+		}
+	}
+
+	logProblems(problems)
+	if vendordep.HasErrors(problems) {
+		if force {
+			slog.Warn("Installing anyways because of --force", "names", names)
+			return nil
+		}
+		// the vendordep being added comes after the ones it requires
+		name := newDeps[len(newDeps) - 1].Name
+		if len(newDeps) > 1 {
+			return fmt.Errorf("%s or the vendordeps it requires are not compatible with this project, use --force to install them anyways", name)
+		}
+		return fmt.Errorf("%s is not compatible with this project, use --force to install it anyways", name)
+	}
+
+	return nil
+}
+
+// vendordepcheckCmd represents the vendordep check command
+var vendordepcheckCmd = &cobra.Command{
+	Use: "check",
+	Short: "Check your vendordeps for compatibility problems",
+	Long: `Check your installed vendordeps for problems which will stop your
+project from building or running on the robot:
+
+  - vendordeps made for a different year than your project
+  - multiple vendordeps with the same uuid or name
+  - multiple vendordeps using different versions of the same maven artifact
+  - native libraries without roboRIO (linuxathena) support
+  - vendordeps which conflict with another installed vendordep
+  - vendordeps which require a vendordep that isn't installed (a warning)
+
+rph exits with a non-zero exit code when any errors are found, warnings are
+only reported.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return errNotInProject }
+
+		year, err := wpilibYear()
+		if err != nil {
+			slog.Error("Failed to get the project year", "error", err)
+			return err
+		}
+
+		deps, err := vendordep.ListVendorDeps(projectFs)
+		if err != nil {
+			slog.Error("Unable to list vendor deps", "error", err)
+			return err
+		}
+
+		problems := vendordep.Check(deps, year)
+		logProblems(problems)
+
+		if vendordep.HasErrors(problems) {
+			os.Exit(1)
+		}
+		if len(problems) == 0 {
+			slog.Info("No problems found")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepcheckCmd)
+}
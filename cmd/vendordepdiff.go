@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// fetchVersion get a specific version of a vendordep from the marketplace, when
+// the network can't be reached the cache is checked instead
+func fetchVersion(year string, name string, version string) (*vendordep.Vendordep, error) {
+	c, err := vendordep.ParseConstraint(version)
+	if err != nil {
+		return nil, err
+	}
+
+	online, err := vendordep.ResolveOnlineDep(year, name, c)
+	if err == nil {
+		_, dep, _, err := online.Fetch()
+		return dep, err
+	} else if !vendordep.IsNetworkError(err) {
+		return nil, err
+	}
+
+	slog.Warn("Unable to reach the network, using the cache instead", "name", name, "version", version)
+	_, dep, err := vendordep.FindCachedVendorDep(vendordep.Vendordep{ Name: name }, false, c)
+	return dep, err
+}
+
+// findInstalled find an installed vendordep by its name or file name
+func findInstalled(name string) (*vendordep.Vendordep, error) {
+	deps, err := vendordep.ListVendorDeps(projectFs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dep := range deps {
+		if strings.EqualFold(dep.Name, name) || strings.EqualFold(strings.TrimSuffix(dep.FileName, ".json"), name) {
+			return &dep, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not installed", name)
+}
+
+// vendordepdiffCmd represents the vendordep diff command
+var vendordepdiffCmd = &cobra.Command{
+	Use: "diff <name> [fromVersion] <toVersion>",
+	Short: "Show what changed between two versions of a vendordep",
+	Long: `Show what changed between two versions of a vendordep from the
+vendordep marketplace. When only one version is given the installed version of
+the vendordep is compared against it.
+
+Rather than a line by line diff of the files the vendordeps are compared
+field by field, maven urls which were added or removed, dependencies which were
+added, removed or changed version and changes to the platforms and sim mode of
+each dependency are all shown. Lines starting with + were added, - were removed
+and ~ were changed.
+
+Either version may be latest to use the newest version on the marketplace.
+
+Examples:
+  rph vendordep diff photonlib 2025.3.1 # Compare the installed version
+  rph vendordep diff photonlib 2025.1.1 2025.3.1
+  rph vendordep diff photonlib latest --json`,
+	Args: cobra.RangeArgs(2, 3),
+	ValidArgsFunction: vendorDepsComp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJson, err := cmd.Flags().GetBool("json")
+		if err != nil { return err }
+
+		year, err := projectYear(cmd)
+		if err != nil {
+			slog.Error("Failed to get the project year", "error", err)
+			return err
+		}
+
+		name := args[0]
+		var from, to *vendordep.Vendordep
+
+		if len(args) == 2 {
+			if !inProjectDir() { return errNotInProject }
+
+			from, err = findInstalled(name)
+			if err != nil {
+				slog.Error("Failed to find installed vendordep", "name", name, "error", err)
+				return err
+			}
+		} else {
+			from, err = fetchVersion(year, name, args[1])
+			if err != nil {
+				slog.Error("Failed to get vendordep", "name", name, "version", args[1], "error", err)
+				return err
+			}
+		}
+
+		to, err = fetchVersion(year, name, args[len(args) - 1])
+		if err != nil {
+			slog.Error("Failed to get vendordep", "name", name, "version", args[len(args) - 1], "error", err)
+			return err
+		}
+
+		changes := vendordep.Diff(from, to)
+
+		if asJson {
+			if changes == nil {
+				changes = []vendordep.Change{}
+			}
+
+			data, err := json.MarshalIndent(changes, "", "  ")
+			if err != nil {
+				slog.Error("Failed to encode diff", "error", err)
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		vendordep.ShowDiff(os.Stdout, from, to, changes)
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepdiffCmd)
+
+	vendordepdiffCmd.Flags().Bool("json", false, "Output the changes as json.")
+	vendordepdiffCmd.Flags().StringP("year", "y", "", "override the year to search for dependencies in frcmaven.")
+}
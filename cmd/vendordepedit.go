@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"rph/cmd/vendordep"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordepeditCmd represents the vendordep edit command
+var vendordepeditCmd = &cobra.Command{
+	Use: "edit <name>",
+	Short: "Make changes to an installed vendordep",
+	Long: `Make changes to an installed vendordep file. Only the parts of the file
+being changed are rewritten, everything else including fields rph doesn't know
+about, the order of the keys and the formatting is kept exactly as it was.
+
+The lockfile is updated to match the edited file and a copy of it is kept in
+the rph cache so that rph vendordep sync can restore it.
+
+  --pin      set the version of the vendordep and the dependencies which
+             share its version
+  --maven-url replace the maven repositories, e.g. with a mirror
+  --tag      record metadata in the file under "` + vendordep.MetadataKey + `"
+
+Examples:
+  rph vendordep edit photonlib --pin 2025.3.1
+  rph vendordep edit photonlib --maven-url http://mirror.local:8080/maven
+  rph vendordep edit photonlib --tag reason="waiting on a fix"`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: vendorDepsComp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return nil }
+
+		pin, err := cmd.Flags().GetString("pin")
+		if err != nil { return err }
+		mavenUrls, err := cmd.Flags().GetStringSlice("maven-url")
+		if err != nil { return err }
+		tags, err := cmd.Flags().GetStringToString("tag")
+		if err != nil { return err }
+
+		if pin == "" && len(mavenUrls) == 0 && len(tags) == 0 {
+			slog.Error("Nothing to change, pass --pin, --maven-url or --tag")
+			return errors.New("nothing to change")
+		}
+
+		dep, err := findInstalled(args[0])
+		if err != nil {
+			slog.Error("Failed to find installed vendordep", "name", args[0], "error", err)
+			return err
+		}
+
+		data, err := os.ReadFile(filepath.Join(projectDir, dep.Path))
+		if err != nil {
+			slog.Error("Failed to read vendordep", "file", dep.Path, "error", err)
+			return err
+		}
+
+		doc, err := vendordep.ParseDocument(data)
+		if err != nil {
+			slog.Error("Failed to parse vendordep", "file", dep.Path, "error", err)
+			return err
+		}
+
+		if pin != "" {
+			if err := doc.Pin(pin); err != nil {
+				return err
+			}
+		}
+		if len(mavenUrls) > 0 {
+			if err := doc.SetMavenUrls(mavenUrls); err != nil {
+				return err
+			}
+		}
+		// sorted so the tags are always added in the same order
+		for _, k := range slices.Sorted(maps.Keys(tags)) {
+			if err := doc.Tag(k, tags[k]); err != nil {
+				return err
+			}
+		}
+
+		edited, err := vendordep.Rewrite(projectDir, *dep, doc.Bytes())
+		if err != nil {
+			slog.Error("Failed to write edited vendordep", "name", dep.Name, "error", err)
+			return err
+		}
+
+		var changes []string
+		for _, c := range vendordep.Diff(dep, edited) {
+			changes = append(changes, c.String())
+		}
+		if len(tags) > 0 {
+			changes = append(changes, fmt.Sprintf("tagged %d field(s) in %s", len(tags), vendordep.MetadataKey))
+		}
+		slog.Info("Edited vendordep", "name", edited.Name, "changes", strings.Join(changes, "; "))
+
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepeditCmd)
+
+	vendordepeditCmd.Flags().String("pin", "", "Set the version of the vendordep and the dependencies which share its version.")
+	vendordepeditCmd.Flags().StringSlice("maven-url", nil, "Replace the maven repositories the vendordep uses.")
+	vendordepeditCmd.Flags().StringToString("tag", nil, "Record key=value metadata in the vendordep file.")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"rph/cmd/vendordep"
+	"rph/cmd/vendordep/artifactory"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordepfetchartifactsCmd represents the vendordep fetch-artifacts command
+var vendordepfetchartifactsCmd = &cobra.Command{
+	Use: "fetch-artifacts [name...]",
+	Short: "Download the maven artifacts of your vendordeps",
+	Long: `Download every maven artifact your vendordeps need into a local maven
+repository, so your robot code can be built without an internet connection.
+By default every installed vendordep is fetched for the roboRIO and the
+platform you're currently on.
+
+When --artifactory points at other machines running rph mirror serve their
+maven repositories are tried before the vendors own maven repositories.
+
+Once the artifacts are downloaded a gradle snippet pointing at the local maven
+repository is printed, add it to the repositories block of your build.gradle
+or pass --init-script to write a gradle init script instead. When an artifact
+can't be fetched rph exits with an error, unless --allow-missing is passed.
+
+Examples:
+  rph vendordep fetch-artifacts
+  rph vendordep fetch-artifacts photonlib -p linuxathena -p windowsx86-64
+  rph vendordep fetch-artifacts --init-script pit.gradle
+  gradle build --offline --init-script pit.gradle`,
+	ValidArgsFunction: vendorDepsComp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return errNotInProject }
+
+		platforms, err := cmd.Flags().GetStringSlice("platform")
+		if err != nil { return err }
+		debug, err := cmd.Flags().GetBool("debug")
+		if err != nil { return err }
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil { return err }
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil { return err }
+		initScript, err := cmd.Flags().GetString("init-script")
+		if err != nil { return err }
+		allowMissing, err := cmd.Flags().GetBool("allow-missing")
+		if err != nil { return err }
+
+		if len(platforms) == 0 {
+			platforms = []string{ vendordep.RoboRioPlatform, vendordep.HostPlatform() }
+		}
+		if dir == "" {
+			dir = vendordep.MavenCachePath()
+		}
+		dir, err = filepath.Abs(dir)
+		if err != nil { return err }
+
+		deps, err := vendordep.ListVendorDeps(projectFs)
+		if err != nil {
+			slog.Error("Unable to list vendor deps", "error", err)
+			return err
+		}
+
+		var missing []vendordep.Artifact
+		for _, dep := range deps {
+			if len(args) > 0 && !slices.Contains(args, dep.Name) { continue }
+
+			// prefer mirrors over the vendors own maven repositories
+			var mavenUrls []string
+			for _, mirror := range artifactoryUrls(cmd) {
+				if mirror == artifactory.DefaultVendorDepArtifactoryUrl { continue }
+				mavenUrls = append(mavenUrls, strings.TrimRight(mirror, "/") + "/maven")
+			}
+			mavenUrls = append(mavenUrls, dep.MavenUrls...)
+
+			slog.Info("Fetching artifacts", "name", dep.Name, "version", dep.Version)
+			missing = append(missing, vendordep.FetchArtifacts(dir, mavenUrls, dep.Artifacts(platforms, debug), force)...)
+		}
+
+		err = vendordep.MissingArtifactsError(missing, allowMissing)
+		if err != nil { return err }
+
+		if initScript != "" {
+			err = os.WriteFile(initScript, []byte(vendordep.GradleInitScript(dir)), 0644)
+			if err != nil {
+				slog.Error("Failed to write gradle init script", "error", err)
+				return err
+			}
+			slog.Info("Wrote gradle init script", "path", initScript)
+			fmt.Printf("gradle build --init-script %s\n", initScript)
+			return nil
+		}
+
+		fmt.Println(vendordep.GradleRepoSnippet(dir))
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepfetchartifactsCmd)
+
+	vendordepfetchartifactsCmd.Flags().StringSliceP("platform", "p", nil, "The platforms to fetch native artifacts for (default linuxathena and this platform).")
+	vendordepfetchartifactsCmd.Flags().Bool("debug", false, "Fetch the debug variants of native artifacts.")
+	vendordepfetchartifactsCmd.Flags().BoolP("force", "f", false, "Download artifacts again even if they've already been fetched.")
+	vendordepfetchartifactsCmd.Flags().StringP("dir", "d", "", "The local maven repository to download into (default is in the rph cache).")
+	vendordepfetchartifactsCmd.Flags().String("init-script", "", "Write a gradle init script which uses the local maven repository.")
+	vendordepfetchartifactsCmd.Flags().Bool("allow-missing", false, "Only warn about artifacts which can't be fetched.")
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordepgraphCmd represents the vendordep graph command
+var vendordepgraphCmd = &cobra.Command{
+	Use: "graph",
+	Short: "Export a graph of your vendordeps and their maven artifacts",
+	Long: `Export a graph of the installed vendordeps, the maven artifacts each
+of them pulls in, the vendordeps they require and the maven groupIds which are
+shared between vendordeps.
+
+Artifacts which have been downloaded into the rph maven cache with
+rph vendordep fetch-artifacts are labeled with the size of the files which get
+deployed to the roboRIO, so you can see what's taking up space on the robot.
+
+Formats:
+  dot     - Graphviz, render it with dot -Tsvg (default)
+  mermaid - A mermaid flowchart for markdown documentation
+  json    - The nodes and edges as json for scripting
+
+Examples:
+  rph vendordep graph | dot -Tsvg > vendordeps.svg
+  rph vendordep graph -o mermaid`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return errNotInProject }
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil { return err }
+
+		deps, err := vendordep.ListVendorDeps(projectFs)
+		if err != nil {
+			slog.Error("Unable to list vendor deps", "error", err)
+			return err
+		}
+
+		graph := vendordep.BuildGraph(deps, vendordep.MavenCachePath())
+
+		switch format {
+		case "dot":
+			graph.WriteDOT(os.Stdout)
+		case "mermaid":
+			graph.WriteMermaid(os.Stdout)
+		case "json":
+			return graph.WriteJSON(os.Stdout)
+		default:
+			slog.Error("Unknown output format", "format", format)
+			return errors.New("unknown format: " + format)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepgraphCmd)
+
+	vendordepgraphCmd.Flags().StringP("format", "o", "dot", "The output format, one of dot, mermaid or json.")
+}
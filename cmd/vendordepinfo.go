@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordepinfoCmd represents the vendordep info command
+var vendordepinfoCmd = &cobra.Command{
+	Use: "info <name>",
+	Short: "Show information about a vendordep",
+	Long: `Show everything rph knows about a vendordep. The vendordep may either
+be installed in the current project or be found on the vendordep marketplace,
+marketplace vendordeps are not installed.
+
+Examples:
+  rph vendordep info photonlib # An installed vendordep
+  rph vendordep info photonlib-v2025.3.1 # A vendordep from the marketplace
+  rph vendordep info photonlib --json # Output as json for scripting`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: vendorDepsComp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJson, err := cmd.Flags().GetBool("json")
+		if err != nil { return err }
+
+		// outside of a project there's nothing installed to look through
+		var dep *vendordep.Vendordep
+		if projectFs != nil {
+			dep, _ = vendordep.FindVendorDepFromName(args[0], projectFs)
+		}
+		if dep == nil {
+			year, err := projectYear(cmd)
+			if err != nil {
+				slog.Error("Failed to get the project year", "error", err)
+				return err
+			}
+
+			online, err := vendordep.FindAvailableOnlineDep(year, args[0])
+			if err != nil {
+				slog.Error("Vendordep is not installed or on the marketplace", "name", args[0], "year", year)
+				return err
+			}
+
+			_, dep, _, err = online.Fetch()
+			if err != nil {
+				return err
+			}
+		}
+
+		if asJson {
+			data, err := json.MarshalIndent(dep, "", "  ")
+			if err != nil {
+				slog.Error("Failed to encode vendordep", "error", err)
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		vendordep.ShowInfo(os.Stdout, *dep)
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepinfoCmd)
+
+	vendordepinfoCmd.Flags().Bool("json", false, "Output the vendordep as json.")
+	vendordepinfoCmd.Flags().StringP("year", "y", "", "override the year to search for dependencies in frcmaven.")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"rph/cmd/vendordep"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordeplintCmd represents the vendordep lint command
+var vendordeplintCmd = &cobra.Command{
+	Use: "lint [file...]",
+	Short: "Validate vendordep files against the vendordep schema",
+	Long: `Validate vendordep files against the WPILib vendordep schema. Unlike
+the rest of rph, which will happily read a vendordep with typos in it, lint
+reports:
+
+  - unknown fields and missing required fields
+  - fields with the wrong type
+  - invalid uuids, years and file names
+  - empty mavenUrls
+  - unknown platform names and sim modes
+
+When no files are given every vendordep in the current project is linted, pass
+- to read a vendordep from stdin. rph exits with a non-zero exit code when any
+errors are found so that it may be used in CI, warnings are only reported.
+
+Examples:
+  rph vendordep lint
+  rph vendordep lint MyLib.json`,
+	// linting files doesn't need a project
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			superPersistentPreRun(cmd, args)
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files := args
+		if len(files) == 0 {
+			if !inProjectDir() { return errNotInProject }
+
+			var err error
+			files, err = filepath.Glob(filepath.Join(projectDir, "vendordeps", "*.json"))
+			if err != nil { return err }
+		}
+
+		var problems []vendordep.Problem
+		for _, file := range files {
+			var data []byte
+			var err error
+			if file == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(file)
+			}
+			if err != nil {
+				slog.Error("Failed to read vendordep", "file", file, "error", err)
+				return err
+			}
+
+			problems = append(problems, vendordep.Validate(file, data)...)
+		}
+
+		logProblems(problems)
+
+		if vendordep.HasErrors(problems) {
+			os.Exit(1)
+		}
+		if len(problems) == 0 {
+			slog.Info("No problems found", "files", len(files))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordeplintCmd)
+}
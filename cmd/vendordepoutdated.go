@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"rph/cmd/vendordep"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type outdatedDep struct {
+	Name string `json:"name"`
+	FileName string `json:"fileName"`
+	Installed string `json:"installed"`
+	Latest string `json:"latest"`
+	Source string `json:"source"`
+	Url string `json:"url"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+}
+
+var outdatedFormats = []string{ "table", "json", "github" }
+
+// vendordepoutdatedCmd represents the vendordep outdated command
+var vendordepoutdatedCmd = &cobra.Command{
+	Use: "outdated",
+	Args: cobra.NoArgs,
+	Short: "List installed vendordeps which have a newer version",
+	Long: `List every installed vendordep which is behind the newest version on
+the vendordep marketplace or the newest version found at its jsonUrl. Nothing
+is changed, to actually update your vendordeps use rph vendordep update.
+Versions outside of the constraint a vendordep was added with are ignored.
+
+rph exits with a non-zero exit code when any vendordep is outdated, or couldn't
+be checked, so that it may be used in CI.
+
+Formats:
+  table  - A human readable table (default)
+  json   - A json array for scripting
+  github - GitHub Actions warning annotations
+
+Examples:
+  rph vendordep outdated
+  rph vendordep outdated -o github`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return errNotInProject }
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil { return err }
+		// checked up front so a typo doesn't cost a round trip to every source
+		if !slices.Contains(outdatedFormats, format) {
+			slog.Error("Unknown output format", "format", format)
+			return errors.New("unknown format: " + format)
+		}
+
+		year, err := projectYear(cmd)
+		if err != nil {
+			slog.Error("Failed to get the project year", "error", err)
+			return err
+		}
+
+		deps, err := vendordep.ListVendorDeps(projectFs)
+		if err != nil {
+			slog.Error("Unable to list vendor deps", "error", err)
+			return err
+		}
+
+		lock, err := vendordep.LoadLock(projectDir)
+		if err != nil { return err }
+
+		// a vendordep we couldn't check might be outdated, that shouldn't look
+		// like everything is up to date
+		unchecked := 0
+		var outdated []outdatedDep
+		for _, u := range vendordep.FindUpdates(deps, year, lock.Constraints()) {
+			if u.Err != nil && u.Latest == nil {
+				slog.Error("Unable to check vendordep for updates", "name", u.Installed.Name, "error", u.Err)
+				unchecked++
+			}
+			if !u.Outdated() { continue }
+
+			o := outdatedDep{
+				Name: u.Installed.Name,
+				FileName: u.Installed.FileName,
+				Installed: u.Installed.Version,
+				Latest: u.Latest.Version,
+				Source: u.Source,
+				Url: u.Url,
+			}
+			if !u.LastModTime.IsZero() {
+				o.ReleaseDate = u.LastModTime.Format(time.RFC3339)
+			}
+			outdated = append(outdated, o)
+		}
+
+		switch format {
+		case "table":
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tINSTALLED\tLATEST\tRELEASED\tSOURCE")
+			for _, o := range outdated {
+				released := o.ReleaseDate
+				if released == "" { released = "-" }
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", o.Name, o.Installed, o.Latest, released, o.Source)
+			}
+			w.Flush()
+		case "json":
+			if outdated == nil {
+				outdated = []outdatedDep{}
+			}
+			data, err := json.MarshalIndent(outdated, "", "  ")
+			if err != nil { return err }
+			fmt.Println(string(data))
+		case "github":
+			for _, o := range outdated {
+				fmt.Printf("::warning file=%s,title=Outdated vendordep::%s %s is installed but %s is available from the %s\n",
+					path.Join("vendordeps", o.FileName), o.Name, o.Installed, o.Latest, o.Source)
+			}
+		}
+
+		// the output already says what's wrong, the error is only for the
+		// exit code
+		if len(outdated) > 0 || unchecked > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d vendordeps are outdated and %d couldn't be checked", len(outdated), unchecked)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepoutdatedCmd)
+
+	vendordepoutdatedCmd.Flags().StringP("format", "o", "table", "The output format, one of table, json or github.")
+	vendordepoutdatedCmd.Flags().StringP("year", "y", "", "override the year to search for dependencies in frcmaven.")
+}
@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"log/slog"
-	"os"
-	"path/filepath"
 	"rph/cmd/vendordep"
+	"slices"
 
 	"github.com/spf13/cobra"
 )
@@ -14,8 +13,9 @@ var vendordepremoveCmd = &cobra.Command{
 	Use: "remove",
 	Short: "Remove a vendordep",
 	Long: `Remove a vendordep, by default this will move the vendordep file into
-a safe place just incase you wish to undo this action to make sure it's been
-removed from your disk you may use the -f flag.`,
+a safe place just incase you wish to undo this action with
+rph vendordep restore. To make sure it's been removed from your disk you may
+use the -f flag.`,
 	Aliases: []string{ "rm" },
 	Args: cobra.MinimumNArgs(1),
 	ValidArgsFunction: vendorDepsComp,
@@ -26,24 +26,23 @@ removed from your disk you may use the -f flag.`,
 		if err != nil { return err }
 
 		for _, n := range args {
-			dep, err := vendordep.FindVendorDepFromName(args[0], projectFs)
+			dep, err := vendordep.FindVendorDepFromName(n, projectFs)
 			if err != nil {
 				slog.Error("Failed to get vendor dep from name", "name", n, "error", err)
 				return err
 			}
 
-			if force {
-				err = os.Remove(filepath.Join(projectDir, "vendordeps", dep.FileName))
-				if err != nil {
-					slog.Error("Failed to remove vendor dep", "error", err)
-					return err
-				}
-			} else {
-				err = vendordep.Trash(filepath.Join(projectDir, "vendordeps", dep.FileName))
-				if err != nil {
-					slog.Error("Failed to move vendor dep to trash", "error", err)
-					return err
-				}
+			installed, err := vendordep.ListVendorDeps(projectFs)
+			if err != nil { return err }
+			for _, d := range vendordep.Dependents(installed, dep.UUID) {
+				if slices.Contains(args, d.Name) { continue }
+				slog.Warn("Vendordep is still required by another vendordep", "name", dep.Name, "requiredBy", d.Name)
+			}
+
+			err = vendordep.Uninstall(projectDir, *dep, !force)
+			if err != nil {
+				slog.Error("Failed to remove vendor dep", "name", n, "error", err)
+				return err
 			}
 		}
 
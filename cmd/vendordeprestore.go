@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"log/slog"
+	"rph/cmd/vendordep"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func trashComp(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	entries, err := vendordep.ListTrash()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name, toComplete) {
+			completions = append(completions, e.Name)
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// vendordeprestoreCmd represents the vendordep restore command
+var vendordeprestoreCmd = &cobra.Command{
+	Use: "restore <name|id>...",
+	Short: "Restore a vendordep from the trash",
+	Long: `Restore a vendordep from the trash into the current project. When
+there are multiple trashed vendordeps with the same name the most recently
+trashed one is restored, to pick a specific one pass in its id from
+rph vendordep trash list. A vendordep which would replace a file already in
+the project is only restored with --force.`,
+	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: trashComp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return errNotInProject }
+
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil { return err }
+
+		for _, arg := range args {
+			entry, err := vendordep.FindTrash(arg)
+			if err != nil {
+				slog.Error("Unable to find vendordep in the trash", "name", arg, "error", err)
+				return err
+			}
+
+			err = vendordep.Restore(projectDir, *entry, force)
+			if err != nil {
+				slog.Error("Failed to restore vendordep", "name", arg, "error", err)
+				return err
+			}
+
+			slog.Info("Restored vendordep", "name", entry.Name, "version", entry.Version, "file", entry.FileName)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordeprestoreCmd)
+
+	vendordeprestoreCmd.Flags().BoolP("force", "f", false, "Replace a vendordep which is already installed.")
+}
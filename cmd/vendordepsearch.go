@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+	"rph/cmd/vendordep/artifactory"
+	"rph/utils"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordepsearchCmd represents the vendordep search command
+var vendordepsearchCmd = &cobra.Command{
+	Use: "search [query]",
+	Short: "Search the vendordep marketplace",
+	Long: `Search the vendordep marketplace for vendordeps with a name similar to
+query. The newest versions of each matching vendordep are listed, newest first,
+along with when they were published. Use --versions to list more of them, or 0
+to list every version. Versions which are installed in the current project are
+marked.
+
+By default the marketplace for your projects year is searched, use --year to
+search other years.
+
+With --artifact the maven artifacts hosted on the artifactory are searched
+instead, by groupId[:artifactId[:version]]. Groups under the groupId are
+searched too, so com.revrobotics finds com.revrobotics.frc. Versions used by an
+installed vendordep are marked.
+
+Examples:
+  rph vendordep search photon
+  rph vendordep search rev -y 2024 -y 2025
+  rph vendordep search photonlib -n 0
+  rph vendordep search --artifact com.revrobotics`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifact, err := cmd.Flags().GetString("artifact")
+		if err != nil { return err }
+
+		// it's fine to search outside of a project, nothing will be installed
+		var installed []vendordep.Vendordep
+		if projectFs != nil {
+			installed, _ = vendordep.ListVendorDeps(projectFs)
+		}
+
+		if artifact != "" {
+			if len(args) > 0 {
+				return errors.New("a query can't be used with --artifact")
+			}
+			return searchArtifacts(artifact, installed)
+		}
+		if len(args) == 0 {
+			return errors.New("a query or --artifact is required")
+		}
+
+		years, err := cmd.Flags().GetStringSlice("year")
+		if err != nil { return err }
+		limit, err := cmd.Flags().GetInt("versions")
+		if err != nil { return err }
+		if limit < 0 {
+			return fmt.Errorf("invalid number of versions: %d", limit)
+		}
+
+		if len(years) == 0 {
+			year, err := wpilibYear()
+			if err != nil {
+				slog.Error("Failed to get the project year, use --year to pick one", "error", err)
+				return err
+			}
+			years = []string{ year }
+		}
+
+		type result struct {
+			name string
+			score int
+			versions []vendordep.OnlineVendordep
+			// older versions which aren't listed
+			hidden int
+		}
+		results := map[string]*result{}
+
+		for _, year := range years {
+			online, err := vendordep.ListAvailableOnlineDeps(year)
+			if err != nil {
+				slog.Error("Unable to list the marketplace", "year", year, "error", err)
+				return err
+			}
+
+			for name, versions := range online {
+				score, ok := utils.FuzzyMatch(args[0], name)
+				if !ok { continue }
+
+				r, ok := results[name]
+				if !ok {
+					r = &result{ name: name, score: score }
+					results[name] = r
+				}
+				r.versions = append(r.versions, versions...)
+			}
+		}
+
+		if len(results) == 0 {
+			slog.Info("No vendordeps found", "query", args[0])
+			return nil
+		}
+
+		sorted := make([]*result, 0, len(results))
+		for _, r := range results {
+			vendordep.SortVersions(r.versions)
+			if limit > 0 && len(r.versions) > limit {
+				r.hidden = len(r.versions) - limit
+				r.versions = r.versions[:limit]
+			}
+			sorted = append(sorted, r)
+		}
+		slices.SortFunc(sorted, func(a, b *result) int {
+			if a.score != b.score {
+				return b.score - a.score
+			}
+			return strings.Compare(a.name, b.name)
+		})
+
+		// only the versions which are listed are stat'd, each one can be an
+		// http request when the artifactory can't be searched
+		var all []*vendordep.OnlineVendordep
+		for _, r := range sorted {
+			for i := range r.versions {
+				all = append(all, &r.versions[i])
+			}
+		}
+		vendordep.StatAll(all)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, r := range sorted {
+			fmt.Fprintln(w, r.name)
+			for _, v := range r.versions {
+				modified := "-"
+				if !v.LastModTime.IsZero() {
+					modified = v.LastModTime.Format(time.DateOnly)
+				}
+
+				mark := ""
+				if slices.ContainsFunc(installed, v.Matches) {
+					mark = "(installed)"
+				}
+
+				fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", r.name + "-" + v.Version, v.Year, modified, mark)
+			}
+			if r.hidden > 0 {
+				fmt.Fprintf(w, "  ... %d older versions, use -n 0 to list them\n", r.hidden)
+			}
+		}
+
+		return w.Flush()
+	},
+}
+
+// searchArtifacts list the maven artifacts on the artifactory which match coords
+func searchArtifacts(coords string, installed []vendordep.Vendordep) error {
+	artifacts, err := vendordep.SearchArtifacts(coords)
+	if errors.Is(err, artifactory.ErrSearchUnavailable) {
+		slog.Error("The artifactory doesn't allow searching for artifacts", "error", err)
+		return err
+	} else if err != nil {
+		slog.Error("Unable to search for artifacts", "coords", coords, "error", err)
+		return err
+	}
+
+	if len(artifacts) == 0 {
+		slog.Info("No artifacts found", "coords", coords)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	last := ""
+	for _, a := range artifacts {
+		if name := a.GroupId + ":" + a.ArtifactId; name != last {
+			fmt.Fprintln(w, name)
+			last = name
+		}
+
+		var users []string
+		for _, dep := range installed {
+			if dep.Uses(a.GroupId, a.ArtifactId, a.Version) {
+				users = append(users, dep.Name)
+			}
+		}
+		mark := ""
+		if len(users) > 0 {
+			mark = "(used by " + strings.Join(users, ", ") + ")"
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", a.Version, a.Repo, mark)
+	}
+
+	return w.Flush()
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepsearchCmd)
+
+	vendordepsearchCmd.Flags().String("artifact", "", "Search for maven artifacts by groupId[:artifactId[:version]] instead.")
+	vendordepsearchCmd.Flags().StringSliceP("year", "y", nil, "The years to search the marketplace for (default is the project year).")
+	vendordepsearchCmd.Flags().IntP("versions", "n", 5, "How many versions of each vendordep to list, 0 lists every version.")
+}
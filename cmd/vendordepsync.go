@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"log/slog"
+	"rph/cmd/vendordep"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordepsyncCmd represents the vendordep sync command
+var vendordepsyncCmd = &cobra.Command{
+	Use: "sync",
+	Short: "Install exactly the vendordeps in the lockfile",
+	Long: `Install exactly the vendordeps recorded in vendordeps/rph.lock. Locked
+vendordeps are restored from the rph cache when possible and otherwise
+downloaded from where they were originally installed from. Vendordeps which
+are not in the lockfile are left alone unless --prune is used, in which case
+they're moved into the trash.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return errNotInProject }
+
+		prune, err := cmd.Flags().GetBool("prune")
+		if err != nil { return err }
+
+		lock, err := vendordep.LoadLock(projectDir)
+		if err != nil { return err }
+
+		err = lock.Sync(projectDir, prune)
+		if err != nil {
+			slog.Error("Failed to sync vendordeps", "error", err)
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepsyncCmd)
+
+	vendordepsyncCmd.Flags().Bool("prune", false, "Move vendordeps which aren't in the lockfile into the trash.")
+}
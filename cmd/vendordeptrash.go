@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// vendordeptrashCmd represents the vendordep trash command
+var vendordeptrashCmd = &cobra.Command{
+	Use: "trash",
+	Short: "Manage removed vendordeps",
+	Long: `Manage the vendordeps which have been moved into the trash by
+rph vendordep remove, update or sync. Trashed vendordeps may be put back into a
+project using rph vendordep restore.`,
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordeptrashCmd)
+}
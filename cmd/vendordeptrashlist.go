@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordeptrashlistCmd represents the vendordep trash list command
+var vendordeptrashlistCmd = &cobra.Command{
+	Use: "list",
+	Short: "List the vendordeps in the trash",
+	Long: `List the vendordeps in the trash, newest first.`,
+	Aliases: []string{ "ls" },
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := vendordep.ListTrash()
+		if err != nil {
+			slog.Error("Unable to list the trash", "error", err)
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tVERSION\tTRASHED\tPROJECT")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.ID, e.Name, e.Version, e.TrashedAt.Format(time.DateTime), e.Project)
+		}
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	vendordeptrashCmd.AddCommand(vendordeptrashlistCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"rph/cmd/vendordep"
+	"rph/utils"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordeptrashpurgeCmd represents the vendordep trash purge command
+var vendordeptrashpurgeCmd = &cobra.Command{
+	Use: "purge",
+	Short: "Permanently delete vendordeps in the trash",
+	Long: `Permanently delete vendordeps in the trash. By default everything is
+deleted, use --older-than to only delete old vendordeps.
+
+Examples:
+  rph vendordep trash purge
+  rph vendordep trash purge --older-than 30d`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, err := cmd.Flags().GetString("older-than")
+		if err != nil { return err }
+
+		var age time.Duration
+		if olderThan != "" {
+			age, err = utils.ParseDuration(olderThan)
+			if err != nil {
+				slog.Error("Invalid duration", "duration", olderThan, "error", err)
+				return err
+			}
+			// a negative age would purge everything, that's never what a
+			// typo meant
+			if age < 0 {
+				slog.Error("Duration can't be negative", "duration", olderThan)
+				return fmt.Errorf("invalid duration: %s", olderThan)
+			}
+		}
+
+		purged, err := vendordep.PurgeTrash(age)
+		if err != nil {
+			slog.Error("Failed to purge the trash", "error", err)
+			return err
+		}
+
+		slog.Info("Purged the trash", "removed", purged)
+		return nil
+	},
+}
+
+func init() {
+	vendordeptrashCmd.AddCommand(vendordeptrashpurgeCmd)
+
+	vendordeptrashpurgeCmd.Flags().String("older-than", "", "Only delete vendordeps trashed longer than this ago, e.g. 30d, 2w or 12h.")
+}
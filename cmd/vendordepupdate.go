@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// vendordepupdateCmd represents the vendordep update command
+var vendordepupdateCmd = &cobra.Command{
+	Use: "update [name...]",
+	Short: "Update your installed vendordeps",
+	Long: `Update your installed vendordeps to their newest version. By default
+every installed vendordep is updated, you may pass in the names of the
+vendordeps you'd like to update instead.
+
+Each vendordep is checked against the vendordep marketplace for your projects
+year as well as the jsonUrl found inside of the vendordep. A copy of the old
+vendordep file is put in the trash so that the update may be undone, and the
+lockfile is updated to match.
+
+Vendordeps which aren't on the marketplace are updated using the jsonUrl the
+vendor published inside of them, redirects are followed and so is the new
+jsonUrl when a vendor moves their vendordep somewhere else. Vendordeps without
+a jsonUrl which aren't on the marketplace either are reported as manual only,
+you'll have to get a new version from the vendor yourself.
+
+When run in a terminal you're asked before each vendordep is updated, pass
+--yes to update everything without asking.
+
+Vendordeps added with a version constraint, e.g. photonlib@^2025.3, are only
+updated to versions which satisfy it. Add the vendordep again with a new
+constraint to change it.
+
+Examples:
+  rph vendordep update # Update everything
+  rph vendordep update photonlib --dry-run # See what would be updated`,
+	Aliases: []string{ "up" },
+	ValidArgsFunction: vendorDepsComp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return nil }
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil { return err }
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil { return err }
+		// there's nobody to ask when we're not in a terminal
+		yes = yes || !isatty.IsTerminal(os.Stdin.Fd())
+
+		year, err := projectYear(cmd)
+		if err != nil {
+			slog.Error("Failed to get the project year", "error", err)
+			return err
+		}
+
+		installed, err := vendordep.ListVendorDeps(projectFs)
+		if err != nil {
+			slog.Error("Unable to list vendor deps", "error", err)
+			return err
+		}
+
+		var deps []vendordep.Vendordep
+		for _, dep := range installed {
+			if len(args) == 0 || slices.Contains(args, dep.Name) {
+				deps = append(deps, dep)
+			}
+		}
+
+		for _, n := range args {
+			if !slices.ContainsFunc(deps, func(d vendordep.Vendordep) bool { return d.Name == n }) {
+				slog.Warn("Vendordep is not installed", "name", n)
+			}
+		}
+
+		lock, err := vendordep.LoadLock(projectDir)
+		if err != nil { return err }
+
+		updates := vendordep.FindUpdates(deps, year, lock.Constraints())
+
+		if dryRun {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tINSTALLED\tLATEST\tSOURCE")
+			for _, u := range updates {
+				if u.Manual {
+					fmt.Fprintf(w, "%s\t%s\t-\tmanual only\n", u.Installed.Name, u.Installed.Version)
+				}
+				if u.Err != nil && u.Latest == nil {
+					fmt.Fprintf(w, "%s\t%s\t-\tunable to check\n", u.Installed.Name, u.Installed.Version)
+				}
+				if !u.Outdated() { continue }
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.Installed.Name, u.Installed.Version, u.Latest.Version, u.Source)
+			}
+			return w.Flush()
+		}
+
+		failed := 0
+		for _, u := range updates {
+			if u.Err != nil && u.Latest == nil {
+				slog.Error("Unable to check vendordep for updates", "name", u.Installed.Name, "error", u.Err)
+				failed++
+				continue
+			}
+			if u.Manual {
+				slog.Warn("Vendordep has no jsonUrl and isn't on the marketplace, it can only be updated manually", "name", u.Installed.Name, "version", u.Installed.Version)
+				continue
+			}
+			if !u.Outdated() {
+				slog.Info("Vendordep is up to date", "name", u.Installed.Name, "version", u.Installed.Version)
+				continue
+			}
+
+			if !yes {
+				update, err := confirm(fmt.Sprintf("Update %s from %s to %s?", u.Installed.Name, u.Installed.Version, u.Latest.Version), "From " + u.Url)
+				if err != nil { return err }
+
+				if !update {
+					slog.Info("Skipping vendordep", "name", u.Installed.Name)
+					continue
+				}
+			}
+
+			constraint := ""
+			if e := lock.Find(u.Installed.FileName); e != nil {
+				constraint = e.Constraint
+			}
+
+			_, err = vendordep.Replace(projectDir, u.Installed, u.Data, u.Url, constraint)
+			if err != nil {
+				slog.Error("Failed to install updated vendordep", "name", u.Latest.Name, "error", err)
+				return err
+			}
+
+			slog.Info("Updated vendordep", "name", u.Latest.Name, "from", u.Installed.Version, "to", u.Latest.Version)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("unable to check %d vendordeps for updates", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepupdateCmd)
+
+	vendordepupdateCmd.Flags().Bool("dry-run", false, "Show what would be updated without changing anything.")
+	vendordepupdateCmd.Flags().Bool("yes", false, "Update every vendordep without asking.")
+	vendordepupdateCmd.Flags().StringP("year", "y", "", "override the year to search for dependencies in frcmaven.")
+}
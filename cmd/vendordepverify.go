@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"rph/cmd/vendordep"
+
+	"github.com/spf13/cobra"
+)
+
+// vendordepverifyCmd represents the vendordep verify command
+var vendordepverifyCmd = &cobra.Command{
+	Use: "verify",
+	Short: "Check that your vendordeps match the lockfile",
+	Long: `Check that the vendordeps in your project exactly match the ones
+recorded in vendordeps/rph.lock. rph exits with a non-zero exit code if any
+vendordep is missing, modified or not locked.
+
+To restore the locked vendordeps use rph vendordep sync.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !inProjectDir() { return errNotInProject }
+
+		lock, err := vendordep.LoadLock(projectDir)
+		if err != nil { return err }
+
+		drift, err := lock.Verify(projectDir)
+		if err != nil {
+			slog.Error("Failed to verify vendordeps", "error", err)
+			return err
+		}
+
+		for _, d := range drift {
+			slog.Error(d.Error())
+		}
+
+		if len(drift) > 0 {
+			os.Exit(1)
+		}
+
+		slog.Info("Vendordeps match the lockfile")
+		return nil
+	},
+}
+
+func init() {
+	vendordepCmd.AddCommand(vendordepverifyCmd)
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// Config the user's rph config file, every field is optional
+type Config struct {
+	// Artifactory an ordered list of artifactory instances which host the
+	// vendordep marketplace, later entries are only used when earlier ones can't
+	// be reached.
+	Artifactory []string `json:"artifactory"`
+	// CacheTTL how long responses from the artifactory are trusted before
+	// checking if they've changed, e.g. "30m" or "1d". "0" always checks.
+	CacheTTL string `json:"cacheTtl"`
+	// Timeout how long to wait for an artifactory to respond before trying the
+	// next one, e.g. "30s"
+	Timeout string `json:"timeout"`
+}
+
+// LoadConfig read the user's config file, if there is no config file an empty
+// config is returned
+func LoadConfig() (Config, error) {
+	var config Config
+	if ConfigPath == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(ConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(data, &config)
+	return config, err
+}
@@ -8,6 +8,7 @@ import (
 
 const Name = "rph"
 var CachePath string
+var ConfigPath string
 
 func Setup() {
 	var dir, err = os.UserCacheDir()
@@ -16,4 +17,9 @@ func Setup() {
 		os.Exit(1)
 	}
 	CachePath = filepath.Join(dir, Name)
+
+	// not having a config file is fine so don't bother failing
+	if dir, err := os.UserConfigDir(); err == nil {
+		ConfigPath = filepath.Join(dir, Name, "config.json")
+	}
 }
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDuration parse a duration like time.ParseDuration does, but also allow
+// for days (d) and weeks (w) since nobody wants to type 720h.
+func ParseDuration(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration: %s", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch check if every character of query appears in target in order,
+// ignoring case. The returned score is higher for better matches, consecutive
+// characters and matches at the start of words are worth more.
+func FuzzyMatch(query string, target string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(target)
+
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	qi := 0
+	lastMatch := -1
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if unicode.ToLower(t[ti]) != q[qi] { continue }
+
+		score++
+		if lastMatch == ti - 1 {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti - 1]) || (unicode.IsUpper(t[ti]) && unicode.IsLower(t[ti - 1])) {
+			score += 3
+		}
+
+		lastMatch = ti
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+
+	// prefer shorter targets when everything else is equal
+	return score * 100 - len(t), true
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestFuzzyMatchRanking(t *testing.T) {
+	tests := []struct {
+		query string
+		// best match first
+		targets []string
+	}{
+		// exact, then prefix, then a subsequence
+		{ "rev", []string{ "REV", "REVLib", "ReduxLib-Everything" } },
+		// a shorter target wins when everything else is the same
+		{ "photon", []string{ "photonlib", "photonlib-extras" } },
+		// matches at the start of a word are worth more, words are split by
+		// camel case and punctuation
+		{ "lib", []string{ "ReduxLib", "photonlib" } },
+		{ "pl", []string{ "photon-lib", "photonlib" } },
+	}
+
+	for _, tt := range tests {
+		last := 0
+		for i, target := range tt.targets {
+			score, ok := FuzzyMatch(tt.query, target)
+			if !ok {
+				t.Errorf("FuzzyMatch(%q, %q) didn't match", tt.query, target)
+				continue
+			}
+			if i > 0 && score >= last {
+				t.Errorf("FuzzyMatch(%q, %q) = %d, want less than %q's %d", tt.query, target, score, tt.targets[i - 1], last)
+			}
+			last = score
+		}
+	}
+}
+
+func TestFuzzyMatchMisses(t *testing.T) {
+	tests := []struct {
+		query string
+		target string
+		want bool
+	}{
+		{ "", "REVLib", true },
+		{ "REVLIB", "revlib", true },
+		{ "rvlb", "REVLib", true },
+		// characters have to be in order
+		{ "ber", "REVLib", false },
+		{ "revlibs", "REVLib", false },
+		{ "phoenix", "photonlib", false },
+		{ "a", "", false },
+	}
+
+	for _, tt := range tests {
+		if _, ok := FuzzyMatch(tt.query, tt.target); ok != tt.want {
+			t.Errorf("FuzzyMatch(%q, %q) matched = %v, want %v", tt.query, tt.target, ok, tt.want)
+		}
+	}
+}
@@ -37,5 +37,5 @@ func FindEntryDirInParents(startPath string, lookingFor string) (string, error)
 		currentPath = filepath.Dir(currentPath)
 	}
 
-	return "", fmt.Errorf(lookingFor + " not found")
+	return "", fmt.Errorf("%s not found", lookingFor)
 }